@@ -0,0 +1,183 @@
+package openpay
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Card details inferred from the bank identification number (BIN), the leading
+// digits of a card number
+type BINInfo struct {
+	// Available options are: visa, mastercard, carnet, american_express
+	Brand CardBrand
+
+	// Available options are: debit, credit, cash; empty if unknown
+	Type CardType
+
+	// Issuer bank code, if known
+	BankCode string
+
+	// Issuer bank name, if known
+	BankName string
+
+	// Issuer country in ISO_3166-1 format, if known
+	Country string
+
+	// Valid card number lengths
+	Lengths []int
+
+	// Length of the card security code
+	CVVLength int
+
+	// Whether monthly installments are supported, nil if unknown
+	Installments *bool
+}
+
+// ValidLength returns true if the card number length is valid for the BIN
+func (b *BINInfo) ValidLength(n int) bool {
+	for _, l := range b.Lengths {
+		if l == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Single entry on the BIN table
+type binRange struct {
+	from string
+	to   string
+	info BINInfo
+}
+
+//go:embed bins.csv
+var embeddedBINs string
+
+// Ranges on the embedded table, restored by 'ResetBINTable'
+var defaultBINs []binRange
+
+// Active BIN table
+var bins struct {
+	sync.RWMutex
+	list []binRange
+}
+
+func init() {
+	var err error
+	if defaultBINs, err = parseBINTable(strings.NewReader(embeddedBINs)); err != nil {
+		panic(err)
+	}
+	ResetBINTable()
+}
+
+// LookupBIN returns the details available locally for a card number, or
+// partial card number of at least 6 digits. The most specific matching range
+// is used.
+func LookupBIN(number string) (*BINInfo, bool) {
+	if len(number) < 6 {
+		return nil, false
+	}
+	bins.RLock()
+	defer bins.RUnlock()
+
+	var match *binRange
+	for i := range bins.list {
+		r := &bins.list[i]
+		if len(number) < len(r.from) {
+			continue
+		}
+		prefix := number[:len(r.from)]
+		if prefix < r.from || prefix > r.to {
+			continue
+		}
+		// Later entries take precedence on ranges of the same specificity
+		if match == nil || len(r.from) >= len(match.from) {
+			match = r
+		}
+	}
+	if match == nil {
+		return nil, false
+	}
+	info := match.info
+	info.Lengths = append([]int(nil), info.Lengths...)
+	return &info, true
+}
+
+// LoadBINTable adds the ranges provided in CSV format to the BIN table, using
+// the same columns as the embedded table:
+//
+//	from,to,brand,type,bank_code,bank_name,country,lengths,cvv_length,installments
+//
+// Lines starting with '#' are ignored. Ranges loaded later take precedence over
+// existing ones of the same specificity, so this can be used to update or
+// extend the embedded table.
+func LoadBINTable(r io.Reader) error {
+	list, err := parseBINTable(r)
+	if err != nil {
+		return err
+	}
+	bins.Lock()
+	bins.list = append(bins.list, list...)
+	bins.Unlock()
+	return nil
+}
+
+// ResetBINTable discards all ranges added with 'LoadBINTable', restoring the
+// embedded table
+func ResetBINTable() {
+	bins.Lock()
+	bins.list = append([]binRange(nil), defaultBINs...)
+	bins.Unlock()
+}
+
+// Decode BIN ranges in CSV format
+func parseBINTable(r io.Reader) ([]binRange, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 10
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []binRange
+	for _, rec := range records {
+		for i := range rec {
+			rec[i] = strings.TrimSpace(rec[i])
+		}
+		if len(rec[0]) != len(rec[1]) || !isDigits(rec[0]) || !isDigits(rec[1]) {
+			return nil, fmt.Errorf("invalid BIN range: %s-%s", rec[0], rec[1])
+		}
+		info := BINInfo{
+			Brand:    CardBrand(rec[2]),
+			Type:     CardType(rec[3]),
+			BankCode: rec[4],
+			BankName: rec[5],
+			Country:  rec[6],
+		}
+		for _, l := range strings.Fields(rec[7]) {
+			n, err := strconv.Atoi(l)
+			if err != nil {
+				return nil, fmt.Errorf("invalid length for BIN range %s: %s", rec[0], l)
+			}
+			info.Lengths = append(info.Lengths, n)
+		}
+		if info.CVVLength, err = strconv.Atoi(rec[8]); err != nil {
+			return nil, fmt.Errorf("invalid CVV length for BIN range %s: %s", rec[0], rec[8])
+		}
+		if rec[9] != "" {
+			v, err := strconv.ParseBool(rec[9])
+			if err != nil {
+				return nil, fmt.Errorf("invalid installments value for BIN range %s: %s", rec[0], rec[9])
+			}
+			info.Installments = &v
+		}
+		list = append(list, binRange{from: rec[0], to: rec[1], info: info})
+	}
+	return list, nil
+}
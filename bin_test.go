@@ -0,0 +1,54 @@
+package openpay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupBIN(t *testing.T) {
+	cases := map[string]CardBrand{
+		"4111111111111111": BrandVisa,
+		"5555555555554444": BrandMastercard,
+		"2221000000000009": BrandMastercard,
+		"345678000000007":  BrandAmericanExpress,
+		"5062990000000002": BrandCarnet,
+	}
+	for number, brand := range cases {
+		info, ok := LookupBIN(number)
+		if !ok || info.Brand != brand {
+			t.Errorf("%s: expected %s brand, got %+v", number, brand, info)
+		}
+	}
+	if info, _ := LookupBIN("345678"); info.CVVLength != 4 || !info.ValidLength(15) {
+		t.Error("invalid american express details")
+	}
+	if _, ok := LookupBIN("9999999999999995"); ok {
+		t.Error("unexpected match for unknown BIN")
+	}
+	if _, ok := LookupBIN("41111"); ok {
+		t.Error("unexpected match for a short number")
+	}
+
+	// More specific ranges take precedence
+	t.Cleanup(ResetBINTable)
+	err := LoadBINTable(strings.NewReader("411111,411111,visa,credit,002,BANAMEX,MX,16,3,true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := LookupBIN("4111111111111111")
+	if info.BankName != "BANAMEX" || info.Type != "credit" || info.Installments == nil || !*info.Installments {
+		t.Errorf("invalid data received: %+v", info)
+	}
+	if info, _ := LookupBIN("4242424242424242"); info.BankName != "" {
+		t.Errorf("invalid data received: %+v", info)
+	}
+	if err := LoadBINTable(strings.NewReader("4111,41,visa,,,,,16,3,\n")); err == nil {
+		t.Error("failed to detect invalid range")
+	}
+
+	// Loaded ranges are discarded on reset
+	ResetBINTable()
+	if info, _ := LookupBIN("4111111111111111"); info.BankName != "" || info.Brand != BrandVisa {
+		t.Errorf("invalid data received: %+v", info)
+	}
+}
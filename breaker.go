@@ -0,0 +1,196 @@
+package openpay
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State of a circuit breaker
+type BreakerState int
+
+// Possible circuit breaker states
+const (
+	// Requests flow normally
+	BreakerClosed BreakerState = iota
+
+	// Requests fail immediately without reaching the service
+	BreakerOpen
+
+	// A limited number of probe requests are let through to detect if the
+	// service recovered
+	BreakerHalfOpen
+)
+
+// Returns a text representation of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// CircuitOpenError is returned, without reaching the service, for requests
+// rejected by an open circuit breaker
+type CircuitOpenError struct {
+	// Time at which probe requests will be allowed again
+	Until time.Time
+}
+
+// Returns a descriptive text representation
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open until %s", e.Until.Format(time.RFC3339))
+}
+
+// CircuitBreaker stops sending requests when the service is degraded, failing
+// fast instead of waiting for timeouts. Consecutive server errors, with a 5xx
+// status code, and network failures open the circuit; requests rejected by the
+// service, for example declined cards, are considered successful. A breaker
+// can be shared by several clients and is safe for concurrent use.
+type CircuitBreaker struct {
+	// Consecutive failures required to open the circuit, 5 if not provided
+	Threshold int
+
+	// Time to remain open before letting probe requests through, 30
+	// seconds if not provided
+	Cooldown time.Duration
+
+	// Successful probe requests required to close the circuit again, 1 if
+	// not provided; a single failed probe opens the circuit again
+	Probes int
+
+	// Called on every state transition, for example to switch to a
+	// fallback payment method
+	OnStateChange func(from, to BreakerState)
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openUntil time.Time
+	inFlight  int
+	successes int
+}
+
+// State returns the current state of the breaker
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == BreakerOpen && !time.Now().Before(cb.openUntil) {
+		return BreakerHalfOpen
+	}
+	return cb.state
+}
+
+// Verify a request can be sent, a nil breaker allows all requests
+func (cb *CircuitBreaker) allow() error {
+	if cb == nil {
+		return nil
+	}
+	cb.mu.Lock()
+	from := cb.state
+	if cb.state == BreakerOpen {
+		if time.Now().Before(cb.openUntil) {
+			cb.mu.Unlock()
+			return &CircuitOpenError{Until: cb.openUntil}
+		}
+		cb.state = BreakerHalfOpen
+		cb.inFlight = 0
+		cb.successes = 0
+	}
+	if cb.state == BreakerHalfOpen {
+		if cb.inFlight >= cb.probes() {
+			cb.mu.Unlock()
+			return &CircuitOpenError{Until: cb.openUntil}
+		}
+		cb.inFlight++
+	}
+	to := cb.state
+	cb.mu.Unlock()
+	cb.notify(from, to)
+	return nil
+}
+
+// Register the result of a request allowed by the breaker, 'ctx' is the
+// context the request was sent with
+func (cb *CircuitBreaker) record(ctx context.Context, res *result) {
+	if cb == nil {
+		return
+	}
+	// Requests cancelled by the caller, or exceeding its own deadline, say
+	// nothing about the service health; the client timeout is still a failure
+	cancelled := res.status == 0 && res.err != nil && ctx.Err() != nil
+	failed := res.status >= 500 || (res.status == 0 && res.err != nil)
+
+	cb.mu.Lock()
+	from := cb.state
+	switch {
+	case cb.state == BreakerHalfOpen && cancelled:
+		if cb.inFlight > 0 {
+			cb.inFlight--
+		}
+	case cancelled:
+	case cb.state == BreakerClosed:
+		if !failed {
+			cb.failures = 0
+			break
+		}
+		cb.failures++
+		if cb.failures >= cb.threshold() {
+			cb.open()
+		}
+	case cb.state == BreakerHalfOpen:
+		if cb.inFlight > 0 {
+			cb.inFlight--
+		}
+		if failed {
+			cb.open()
+			break
+		}
+		cb.successes++
+		if cb.successes >= cb.probes() {
+			cb.state = BreakerClosed
+			cb.failures = 0
+		}
+	}
+	to := cb.state
+	cb.mu.Unlock()
+	cb.notify(from, to)
+}
+
+// Open the circuit, must be called holding the lock
+func (cb *CircuitBreaker) open() {
+	cooldown := cb.Cooldown
+	if cooldown == 0 {
+		cooldown = 30 * time.Second
+	}
+	cb.state = BreakerOpen
+	cb.openUntil = time.Now().Add(cooldown)
+	cb.failures = 0
+}
+
+func (cb *CircuitBreaker) threshold() int {
+	if cb.Threshold > 0 {
+		return cb.Threshold
+	}
+	return 5
+}
+
+func (cb *CircuitBreaker) probes() int {
+	if cb.Probes > 0 {
+		return cb.Probes
+	}
+	return 1
+}
+
+// Report a state transition
+func (cb *CircuitBreaker) notify(from, to BreakerState) {
+	if from != to && cb.OnStateChange != nil {
+		cb.OnStateChange(from, to)
+	}
+}
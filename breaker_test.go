@@ -0,0 +1,114 @@
+package openpay_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+
+	var transitions []string
+	breaker := &openpay.CircuitBreaker{
+		Threshold: 3,
+		Cooldown:  50 * time.Millisecond,
+		OnStateChange: func(from, to openpay.BreakerState) {
+			transitions = append(transitions, fmt.Sprintf("%s>%s", from, to))
+		},
+	}
+	opts := srv.Options()
+	opts.CircuitBreaker = breaker
+	client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+
+	// Requests rejected by the service don't open the circuit
+	for i := 0; i < 5; i++ {
+		client.Charges.Get("unknown")
+	}
+	if breaker.State() != openpay.BreakerClosed {
+		t.Fatal("circuit should remain closed")
+	}
+
+	// Consecutive gateway errors
+	rule := srv.Inject(openpaytest.Fault{Path: "webhooks", Times: 4, Status: 502})
+	defer srv.ClearFaults()
+	for i := 0; i < 3; i++ {
+		client.Webhooks.List()
+	}
+	if breaker.State() != openpay.BreakerOpen {
+		t.Fatal("circuit should be open")
+	}
+	_, err := client.Webhooks.List()
+	var open *openpay.CircuitOpenError
+	if !errors.As(err, &open) || open.Until.IsZero() {
+		t.Fatalf("expected circuit open error, got %v", err)
+	}
+	if rule.Applied() != 3 {
+		t.Errorf("requests should not reach the service while open, got %d", rule.Applied())
+	}
+
+	// A failed probe opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	if breaker.State() != openpay.BreakerHalfOpen {
+		t.Fatal("circuit should be half-open")
+	}
+	if _, err := client.Webhooks.List(); errors.As(err, &open) {
+		t.Fatal("probe request should be allowed")
+	}
+	if breaker.State() != openpay.BreakerOpen {
+		t.Fatal("circuit should be open")
+	}
+
+	// A successful probe closes it
+	time.Sleep(60 * time.Millisecond)
+	if _, err := client.Webhooks.List(); err != nil {
+		t.Fatal(err)
+	}
+	if breaker.State() != openpay.BreakerClosed {
+		t.Fatal("circuit should be closed")
+	}
+
+	expected := "[closed>open open>half-open half-open>open open>half-open half-open>closed]"
+	if fmt.Sprint(transitions) != expected {
+		t.Errorf("unexpected transitions: %v", transitions)
+	}
+}
+
+func TestCircuitBreakerCallerDeadline(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+
+	breaker := &openpay.CircuitBreaker{Threshold: 1}
+	opts := srv.Options()
+	opts.Timeout = 1
+	opts.CircuitBreaker = breaker
+	client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+	srv.Inject(openpaytest.Fault{Path: "webhooks", Latency: 1500 * time.Millisecond})
+	defer srv.ClearFaults()
+
+	// Deadlines set by the caller don't open the circuit
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		if _, err := client.WithContext(ctx).Webhooks.List(); err == nil {
+			t.Fatal("expected error")
+		}
+		cancel()
+	}
+	if breaker.State() != openpay.BreakerClosed {
+		t.Fatal("circuit should remain closed")
+	}
+
+	// The client timeout is a service failure
+	if _, err := client.Webhooks.List(); err == nil {
+		t.Fatal("expected error")
+	}
+	if breaker.State() != openpay.BreakerOpen {
+		t.Fatal("circuit should be open")
+	}
+}
@@ -38,116 +38,154 @@ type chargesClient struct {
 }
 
 func (cc *chargesClient) AddCard(card *Card) error {
+	if err := card.Validate(); err != nil {
+		return err
+	}
+	if err := cc.c.checkCard(card); err != nil {
+		return err
+	}
+
 	// Add the card at merchant level
 	b, err := cc.c.request(&requestOptions{
-		endpoint: "cards",
-		method:   http.MethodPost,
-		data:     card,
+		operation: "cards.create",
+		endpoint:  "cards",
+		method:    http.MethodPost,
+		data:      card,
 	})
 	if err != nil {
 		return err
 	}
 
-	json.Unmarshal(b, card)
-	return nil
+	return json.Unmarshal(b, card)
 }
 
 func (cc *chargesClient) Get(txID string) (*Transaction, error) {
 	b, err := cc.c.request(&requestOptions{
-		endpoint: path.Join("charges", txID),
-		method:   http.MethodGet,
-		data:     nil,
+		operation: "charges.get",
+		endpoint:  path.Join("charges", txID),
+		method:    http.MethodGet,
+		data:      nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	tx := &Transaction{}
-	json.Unmarshal(b, tx)
+	if err := json.Unmarshal(b, tx); err != nil {
+		return nil, err
+	}
 	return tx, nil
 }
 
 func (cc *chargesClient) List(req *ChargesListRequest) ([]Transaction, error) {
 	b, err := cc.c.request(&requestOptions{
-		endpoint: "charges",
-		method:   http.MethodGet,
-		data:     req,
+		operation: "charges.list",
+		endpoint:  "charges",
+		method:    http.MethodGet,
+		data:      req,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	var list []Transaction
-	json.Unmarshal(b, &list)
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, err
+	}
 	return list, nil
 }
 
 func (cc *chargesClient) AtStore(charge *ChargeAtStore) (*Transaction, error) {
+	if err := charge.Validate(); err != nil {
+		return nil, err
+	}
+
 	b, err := cc.c.request(&requestOptions{
-		endpoint: "charges",
-		method:   http.MethodPost,
-		data:     charge,
+		operation: "charges.create",
+		endpoint:  "charges",
+		method:    http.MethodPost,
+		data:      charge,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	tx := &Transaction{}
-	json.Unmarshal(b, tx)
+	if err := json.Unmarshal(b, tx); err != nil {
+		return nil, err
+	}
 	return tx, nil
 }
 
 func (cc *chargesClient) AtBank(charge *ChargeAtBank) (*Transaction, error) {
+	if err := charge.Validate(); err != nil {
+		return nil, err
+	}
+
 	b, err := cc.c.request(&requestOptions{
-		endpoint: "charges",
-		method:   http.MethodPost,
-		data:     charge,
+		operation: "charges.create",
+		endpoint:  "charges",
+		method:    http.MethodPost,
+		data:      charge,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	tx := &Transaction{}
-	json.Unmarshal(b, tx)
+	if err := json.Unmarshal(b, tx); err != nil {
+		return nil, err
+	}
 	return tx, nil
 }
 
 func (cc *chargesClient) WithCard(charge *ChargeWithStoredCard) (*Transaction, error) {
+	if err := charge.Validate(); err != nil {
+		return nil, err
+	}
+
 	b, err := cc.c.request(&requestOptions{
-		endpoint: "charges",
-		method:   http.MethodPost,
-		data:     charge,
+		operation: "charges.create",
+		endpoint:  "charges",
+		method:    http.MethodPost,
+		data:      charge,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	tx := &Transaction{}
-	json.Unmarshal(b, tx)
+	if err := json.Unmarshal(b, tx); err != nil {
+		return nil, err
+	}
 	return tx, nil
 }
 
 func (cc *chargesClient) Capture(txID string, amount float32) (*Transaction, error) {
 	b, err := cc.c.request(&requestOptions{
-		endpoint: path.Join("charges", txID, "capture"),
-		method:   http.MethodPost,
-		data:     map[string]float32{"amount":amount},
+		operation: "charges.capture",
+		endpoint:  path.Join("charges", txID, "capture"),
+		method:    http.MethodPost,
+		data:      map[string]float32{"amount": amount},
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	tx := &Transaction{}
-	json.Unmarshal(b, tx)
+	if err := json.Unmarshal(b, tx); err != nil {
+		return nil, err
+	}
 	return tx, nil
 }
 
 func (cc *chargesClient) Refund(txID string, amount float32, description string) (*Transaction, error) {
 	b, err := cc.c.request(&requestOptions{
-		endpoint: path.Join("charges", txID, "refund"),
-		method:   http.MethodPost,
-		data:     map[string]interface{}{
-			"amount": amount,
+		operation: "charges.refund",
+		endpoint:  path.Join("charges", txID, "refund"),
+		method:    http.MethodPost,
+		data: map[string]interface{}{
+			"amount":      amount,
 			"description": description,
 		},
 	})
@@ -156,6 +194,8 @@ func (cc *chargesClient) Refund(txID string, amount float32, description string)
 	}
 
 	tx := &Transaction{}
-	json.Unmarshal(b, tx)
+	if err := json.Unmarshal(b, tx); err != nil {
+		return nil, err
+	}
 	return tx, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,120 @@
+package openpay
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"strings"
+)
+
+// Details extracted from a CLABE, the standardized bank account number used to
+// operate transactions with any bank in Mexico
+type Clabe struct {
+	// Full 18 digits number
+	Number string
+
+	// Bank identifier, first 3 digits
+	BankCode string
+
+	// Bank name, empty if the code is not present in the catalog
+	BankName string
+
+	// Branch (plaza) identifier, 3 digits
+	BranchCode string
+
+	// Account number, 11 digits
+	Account string
+
+	// Verification digit
+	ControlDigit string
+}
+
+//go:embed banks.csv
+var embeddedBanks string
+
+// Bank names by code
+var banks = make(map[string]string)
+
+func init() {
+	cr := csv.NewReader(strings.NewReader(embeddedBanks))
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 2
+	records, err := cr.ReadAll()
+	if err != nil {
+		panic(err)
+	}
+	for _, rec := range records {
+		banks[rec[0]] = rec[1]
+	}
+}
+
+// LookupBank returns the name of the bank with the provided code, if known
+func LookupBank(code string) (string, bool) {
+	name, ok := banks[code]
+	return name, ok
+}
+
+// ParseClabe validates the length and control digit of a CLABE and returns its
+// components. A '*ValidationError' is returned for invalid values.
+func ParseClabe(number string) (*Clabe, error) {
+	v := &validator{}
+	validateClabe(v, number)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	name, _ := LookupBank(number[:3])
+	return &Clabe{
+		Number:       number,
+		BankCode:     number[:3],
+		BankName:     name,
+		BranchCode:   number[3:6],
+		Account:      number[6:17],
+		ControlDigit: number[17:],
+	}, nil
+}
+
+// Validate the account details before registering it
+func (acc *BankAccount) Validate() error {
+	v := &validator{}
+	if strings.TrimSpace(acc.HolderName) == "" {
+		v.add("holder_name", "is required")
+	}
+	validateClabe(v, acc.Clabe)
+	return v.err()
+}
+
+// FillBankDetails validates the account CLABE and sets the 'BankCode' and
+// 'BankName' values accordingly
+func (acc *BankAccount) FillBankDetails() error {
+	c, err := ParseClabe(acc.Clabe)
+	if err != nil {
+		return err
+	}
+	acc.BankCode = c.BankCode
+	if c.BankName != "" {
+		acc.BankName = c.BankName
+	}
+	return nil
+}
+
+func validateClabe(v *validator, number string) {
+	switch {
+	case number == "":
+		v.add("clabe", "is required")
+	case len(number) != 18 || !isDigits(number):
+		v.add("clabe", "must be 18 digits long")
+	case clabeControlDigit(number) != number[17]:
+		v.add("clabe", "invalid control digit")
+	}
+}
+
+// Calculate the verification digit using the first 17 digits, weighted by
+// the 3, 7, 1 sequence
+func clabeControlDigit(number string) byte {
+	weights := [3]int{3, 7, 1}
+	sum := 0
+	for i := 0; i < 17; i++ {
+		sum += (int(number[i]-'0') * weights[i%3]) % 10
+	}
+	return byte('0' + (10-sum%10)%10)
+}
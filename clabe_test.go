@@ -0,0 +1,34 @@
+package openpay
+
+import "testing"
+
+func TestClabe(t *testing.T) {
+	c, err := ParseClabe("012298026516924616")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.BankCode != "012" || c.BankName != "BBVA MEXICO" || c.BranchCode != "298" || c.Account != "02651692461" || c.ControlDigit != "6" {
+		t.Errorf("invalid data received: %+v", c)
+	}
+
+	for _, number := range []string{"", "01229802651692461", "01229802651692461A", "012298026516924617"} {
+		if _, err := ParseClabe(number); err == nil {
+			t.Errorf("invalid clabe not detected: %q", number)
+		}
+	}
+
+	acc := &BankAccount{HolderName: "Juan Hernández Sánchez", Clabe: "002010077777777771"}
+	if err := acc.Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := acc.FillBankDetails(); err != nil || acc.BankCode != "002" || acc.BankName != "BANAMEX" {
+		t.Errorf("unexpected result: %v", err)
+	}
+
+	// Invalid accounts must be rejected before reaching the service
+	client, _ := NewClient("sk_invalid", "invalid", nil)
+	err = client.Customers.AddBankAccount("customer", &BankAccount{HolderName: "Juan", Clabe: "002010077777777772"})
+	if e, ok := err.(*ValidationError); !ok || !e.Has("clabe") {
+		t.Errorf("unexpected result: %v", err)
+	}
+}
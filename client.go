@@ -2,13 +2,16 @@ package openpay
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"net"
 	"net/http"
 	"path"
+	"strings"
 	"time"
 )
 
@@ -28,11 +31,23 @@ type Client struct {
 	Webhooks WebhooksAPI
 
 	c           *http.Client
-	key         string
+	creds       CredentialsProvider
 	merchantID  string
 	apiVersion  string
 	userAgent   string
 	apiEndpoint string
+	logger      *slog.Logger
+	logLevels   LogLevels
+	logBodies   bool
+	observers   []Observer
+	limiter     *limiter
+	breaker     *CircuitBreaker
+	response    *Response
+	production  bool
+	readOnly    bool
+	verified    *verification
+	dryRun      *DryRun
+	ctx         context.Context
 }
 
 // Available configuration options, if not provided sane values will be
@@ -55,13 +70,71 @@ type Options struct {
 
 	// Whether to use test or production environment
 	UseProduction bool
+
+	// Alternative service location, for example a local test server; when
+	// provided 'UseProduction' only enables the production safety checks
+	Endpoint string
+
+	// Custom HTTP transport to use, for example to record or replay traffic;
+	// when provided 'KeepAlive' and 'MaxConnections' are ignored
+	Transport http.RoundTripper
+
+	// Report all requests to the service, nothing is logged if not provided
+	Logger *slog.Logger
+
+	// Level used when reporting each request outcome, if not provided
+	// 'DefaultLogLevels' are used
+	LogLevels *LogLevels
+
+	// Include request and response bodies, redacted, on debug level entries
+	LogBodies bool
+
+	// Receive notifications for every request dispatched to the service, for
+	// example to collect metrics using a 'MetricsCollector'
+	Observers []Observer
+
+	// Client side limits for the pace of requests, no limits are applied if
+	// not provided
+	RateLimits *RateLimits
+
+	// Fail fast while the service is degraded, not used if not provided
+	CircuitBreaker *CircuitBreaker
+
+	// Source of the API key for each request, for example to rotate keys
+	// using a secrets manager; when provided the key passed to 'NewClient'
+	// is ignored
+	Credentials CredentialsProvider
+
+	// Verify the merchant is available using the provided key before the
+	// first request, to detect keys used on the wrong environment
+	VerifyMerchant bool
+
+	// Reject all requests modifying data, for example on reporting scripts
+	ReadOnly bool
+
+	// Capture all requests instead of sending them to the service, each
+	// operation receives a synthetic response
+	DryRun *DryRun
 }
 
 // Network request options
 type requestOptions struct {
-	method   string
-	endpoint string
-	data     interface{}
+	operation string
+	method    string
+	endpoint  string
+	data      interface{}
+}
+
+// Details of a completed request attempt
+type result struct {
+	status      int
+	header      http.Header
+	body        []byte
+	requestBody []byte
+	requestID   string
+	latency     time.Duration
+	attempt     int
+	err         error
 }
 
 // Return sane default configuration values
@@ -76,25 +149,47 @@ func defaultOptions() *Options {
 	}
 }
 
-// NewClient will construct a usable service handler using the provided API key and
-// configuration options, if 'nil' options are provided default sane values will
-// be used
-func NewClient(key, merchantID string, options *Options) (*Client, error) {
-	if key == "" {
-		return nil, errors.New("API key is required")
+// Return a copy of the options using default values for all the settings
+// not provided
+func (o *Options) withDefaults() *Options {
+	opts := *o
+	def := defaultOptions()
+	if opts.Timeout == 0 {
+		opts.Timeout = def.Timeout
 	}
-
-	if merchantID == "" {
-		return nil, errors.New("merchant ID is required")
+	if opts.KeepAlive == 0 {
+		opts.KeepAlive = def.KeepAlive
+	}
+	if opts.MaxConnections == 0 {
+		opts.MaxConnections = def.MaxConnections
+	}
+	if opts.APIVersion == "" {
+		opts.APIVersion = def.APIVersion
 	}
+	return &opts
+}
 
+// NewClient will construct a usable service handler using the provided API key and
+// configuration options, if 'nil' options are provided default sane values will
+// be used; the same applies to any setting not provided. The key can be empty
+// when a credentials provider is set in the options.
+func NewClient(key, merchantID string, options *Options) (*Client, error) {
 	// If no options are provided, use default sane values
 	if options == nil {
 		options = defaultOptions()
 	}
+	options = options.withDefaults()
+	creds, err := credentialsFor(key, options)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(creds, merchantID, options, newHTTPClient(options))
+}
 
+// Return the HTTP client used to reach the service
+func newHTTPClient(options *Options) *http.Client {
 	// Configure base HTTP transport
-	t := &http.Transport{
+	var t http.RoundTripper = &http.Transport{
 		MaxIdleConns:        int(options.MaxConnections),
 		MaxIdleConnsPerHost: int(options.MaxConnections),
 		DialContext: (&net.Dialer{
@@ -103,68 +198,240 @@ func NewClient(key, merchantID string, options *Options) (*Client, error) {
 			DualStack: true,
 		}).DialContext,
 	}
+	if options.Transport != nil {
+		t = options.Transport
+	}
+	return &http.Client{
+		Transport: t,
+		Timeout:   time.Duration(options.Timeout) * time.Second,
+	}
+}
+
+// Setup a client instance using the provided HTTP client
+func newClient(creds CredentialsProvider, merchantID string, options *Options, hc *http.Client) (*Client, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID is required")
+	}
 
 	// Setup main client
 	client := &Client{
-		key:        key,
+		creds:      creds,
 		merchantID: merchantID,
 		apiVersion: options.APIVersion,
 		userAgent:  options.UserAgent,
-		c: &http.Client{
-			Transport: t,
-			Timeout:   time.Duration(options.Timeout) * time.Second,
-		},
+		logger:     options.Logger,
+		logLevels:  DefaultLogLevels,
+		logBodies:  options.LogBodies,
+		observers:  options.Observers,
+		breaker:    options.CircuitBreaker,
+		ctx:        context.Background(),
+		production: options.UseProduction,
+		readOnly:   options.ReadOnly,
+		dryRun:     options.DryRun,
+		c:          hc,
+	}
+
+	if options.LogLevels != nil {
+		client.logLevels = *options.LogLevels
+	}
+
+	if options.RateLimits != nil {
+		client.limiter = newLimiter(options.RateLimits)
+	}
+
+	if options.VerifyMerchant {
+		client.verified = &verification{}
 	}
 
 	// Set client endpoint
-	if options.UseProduction {
+	switch {
+	case options.Endpoint != "":
+		client.apiEndpoint = strings.TrimSuffix(options.Endpoint, "/") + "/"
+	case options.UseProduction:
 		client.apiEndpoint = liveAPI
-	} else {
+	default:
 		client.apiEndpoint = testAPI
 	}
 
-	client.Charges = &chargesClient{c: client}
-	client.Customers = &customersClient{c: client}
-	client.Webhooks = &webhooksClient{c: client}
+	client.bind()
 	return client, nil
 }
 
+// WithContext returns a copy of the client using the provided context for all
+// its requests, including any wait imposed by rate limits. The copy shares its
+// configuration and limits with the original client.
+func (i *Client) WithContext(ctx context.Context) *Client {
+	c := *i
+	c.ctx = ctx
+	c.bind()
+	return &c
+}
+
+// WithResponse returns a copy of the client that stores the metadata of each
+// completed request in 'res', for example:
+//
+//	meta := openpay.Response{}
+//	tx, err := client.WithResponse(&meta).Charges.WithCard(charge)
+//
+// The value is overwritten by every request executed using the copy, so it
+// should not be shared by concurrent calls.
+func (i *Client) WithResponse(res *Response) *Client {
+	*res = Response{}
+	c := *i
+	c.response = res
+	c.bind()
+	return &c
+}
+
+// Setup the API handlers for the client instance; custom implementations,
+// for example mocks, are preserved
+func (i *Client) bind() {
+	if _, ok := i.Charges.(*chargesClient); ok || i.Charges == nil {
+		i.Charges = &chargesClient{c: i}
+	}
+	if _, ok := i.Customers.(*customersClient); ok || i.Customers == nil {
+		i.Customers = &customersClient{c: i}
+	}
+	if _, ok := i.Webhooks.(*webhooksClient); ok || i.Webhooks == nil {
+		i.Webhooks = &webhooksClient{c: i}
+	}
+}
+
 // Dispatch a network request to the service
 func (i *Client) request(r *requestOptions) ([]byte, error) {
+	if i.response != nil {
+		*i.response = Response{}
+	}
+	if i.c == nil || i.creds == nil {
+		return nil, ErrNotConfigured
+	}
+	if i.readOnly && r.method != http.MethodGet {
+		return nil, ErrReadOnly
+	}
+	if err := i.verify(); err != nil {
+		return nil, err
+	}
+
+	// Dry runs are captured locally; they never reach the rate limiter,
+	// circuit breaker, observers or logs, and don't require credentials
+	if i.dryRun != nil {
+		res := i.send(r, 1, "")
+		if i.response != nil {
+			*i.response = res.metadata()
+		}
+		return res.body, nil
+	}
+
+	release, err := i.limiter.acquire(i.ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	res, err := i.execute(r, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rejected credentials are refreshed and the request retried once
+	if res.status == http.StatusUnauthorized {
+		if _, static := i.creds.(StaticKey); !static {
+			i.creds.Expire()
+			first := res
+			if res, err = i.execute(r, 2); err != nil {
+				return nil, err
+			}
+			res.latency += first.latency
+		}
+	}
+
+	if i.response != nil {
+		*i.response = res.metadata()
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	return res.body, nil
+}
+
+// Execute and report a single request attempt, an error is returned if the
+// request can't be sent
+func (i *Client) execute(r *requestOptions, attempt int) (*result, error) {
+	key, err := i.creds.Key(i.ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.breaker.allow(); err != nil {
+		return nil, err
+	}
+	info := i.observeStart(r, attempt)
+	res := i.send(r, attempt, key)
+	i.observeFinish(info, res)
+	i.breaker.record(i.ctx, res)
+	i.limiter.feedback(r, res)
+	i.logRequest(r, res)
+	return res, nil
+}
+
+// Send a single request attempt
+func (i *Client) send(r *requestOptions, attempt int, key string) *result {
 	// Get request endpoint
 	endpoint := i.apiEndpoint + path.Join(i.apiVersion, i.merchantID, r.endpoint)
 
 	// Build request with headers and credentials
 	data, _ := json.Marshal(r.data)
-	req, _ := http.NewRequest(r.method, endpoint, bytes.NewReader(data))
+	req, _ := http.NewRequestWithContext(i.ctx, r.method, endpoint, bytes.NewReader(data))
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Type", "application/json")
-	req.SetBasicAuth(i.key, "")
+	req.SetBasicAuth(key, "")
 	if i.userAgent != "" {
 		req.Header.Add("User-Agent", i.userAgent)
 	}
+	if i.dryRun != nil {
+		out := i.dryRun.capture(r, req, data, i.merchantID)
+		out.attempt = attempt
+		return out
+	}
 
 	// Execute request
+	out := &result{requestBody: data, attempt: attempt}
+	start := time.Now()
+	defer func() {
+		out.latency = time.Since(start)
+	}()
 	res, err := i.c.Do(req)
 	if res != nil {
 		// Properly discard request content to be able to reuse the connection
 		defer io.Copy(ioutil.Discard, res.Body)
 		defer res.Body.Close()
+		out.status = res.StatusCode
+		out.header = res.Header
+		out.requestID = res.Header.Get("X-Request-Id")
 	}
 
 	// Network level errors
 	if err != nil {
-		return nil, err
+		out.err = err
+		return out
 	}
 
 	// Get response contents
-	body, err := ioutil.ReadAll(res.Body)
+	out.body, out.err = ioutil.ReadAll(res.Body)
+	if out.err != nil {
+		return out
+	}
 
 	// Application level errors
 	if res.StatusCode >= 400 {
 		e := &APIError{}
-		json.Unmarshal(body, e)
-		return nil, e
+		json.Unmarshal(out.body, e)
+		if e.HTTPCode == 0 {
+			e.HTTPCode = uint(res.StatusCode)
+		}
+		if e.RequestID != "" {
+			out.requestID = e.RequestID
+		}
+		out.err = e
 	}
-	return body, nil
+	return out
 }
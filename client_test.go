@@ -1,28 +1,33 @@
-package openpay
+package openpay_test
 
 import (
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
 )
 
 func TestClient(t *testing.T) {
 	// API key is required
-	_, err := NewClient("", "", nil)
+	_, err := openpay.NewClient("", "", nil)
 	if err == nil {
 		t.Error("failed to detect missing API key")
 	}
 
-	// Use the test key and merchant id provided in the public documentation
-	client, _ := NewClient("sk_e568c42a6c384b7ab02cd47d2e407cab", "mzdtln0bmtms6o3kck8f", nil)
+	// Run against the fake server, no network access is required
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
 
 	t.Run("Customers", func(t *testing.T) {
-		testCustomer := &Customer{
+		testCustomer := &openpay.Customer{
 			Name:            "Rick",
 			LastName:        "Sanchez",
 			Email:           "rick@mail.com",
 			RequiresAccount: false,
-			Address: Address{
+			Address: openpay.Address{
 				Line1:       "Calle 6 #910",
 				City:        "Cordoba",
 				State:       "VER",
@@ -57,7 +62,7 @@ func TestClient(t *testing.T) {
 		})
 
 		t.Run("List", func(t *testing.T) {
-			list, err := client.Customers.List(&CustomersListRequest{})
+			list, err := client.Customers.List(&openpay.CustomersListRequest{})
 			if err != nil {
 				t.Error(err)
 			}
@@ -75,12 +80,12 @@ func TestClient(t *testing.T) {
 	})
 
 	t.Run("Cards", func(t *testing.T) {
-		testCustomer := &Customer{
+		testCustomer := &openpay.Customer{
 			Name:            "Rick",
 			LastName:        "Sanchez",
 			Email:           "rick@mail.com",
 			RequiresAccount: false,
-			Address: Address{
+			Address: openpay.Address{
 				Line1:       "Calle 6 #910",
 				City:        "Cordoba",
 				State:       "VER",
@@ -90,14 +95,9 @@ func TestClient(t *testing.T) {
 		}
 		client.Customers.Create(testCustomer)
 		defer client.Customers.Delete(testCustomer.ID)
-		card := &Card{
-			HolderName:      fmt.Sprintf("%s %s", testCustomer.Name, testCustomer.LastName),
-			CardNumber:      "4111111111111111",
-			CVV2:            "401",
-			ExpirationMonth: "10",
-			ExpirationYear:  "19",
-			Address:         testCustomer.Address,
-		}
+		card := openpaytest.NewCard()
+		card.HolderName = fmt.Sprintf("%s %s", testCustomer.Name, testCustomer.LastName)
+		card.Address = testCustomer.Address
 
 		t.Run("Add", func(t *testing.T) {
 			card.CustomerID = testCustomer.ID
@@ -118,7 +118,7 @@ func TestClient(t *testing.T) {
 		})
 
 		t.Run("List", func(t *testing.T) {
-			list, err := client.Customers.ListCards(testCustomer.ID, &ListRequest{})
+			list, err := client.Customers.ListCards(testCustomer.ID, &openpay.ListRequest{})
 			if err != nil {
 				t.Error(err)
 			}
@@ -136,12 +136,12 @@ func TestClient(t *testing.T) {
 	})
 
 	t.Run("BankAccounts", func(t *testing.T) {
-		testCustomer := &Customer{
+		testCustomer := &openpay.Customer{
 			Name:            "Rick",
 			LastName:        "Sanchez",
 			Email:           "rick@mail.com",
 			RequiresAccount: false,
-			Address: Address{
+			Address: openpay.Address{
 				Line1:       "Calle 6 #910",
 				City:        "Cordoba",
 				State:       "VER",
@@ -151,9 +151,9 @@ func TestClient(t *testing.T) {
 		}
 		client.Customers.Create(testCustomer)
 		defer client.Customers.Delete(testCustomer.ID)
-		acc := &BankAccount{
+		acc := &openpay.BankAccount{
 			HolderName: "Juan Hernández Sánchez",
-			Clabe: "012298026516924616",
+			Clabe:      "012298026516924616",
 		}
 
 		t.Run("Add", func(t *testing.T) {
@@ -174,7 +174,7 @@ func TestClient(t *testing.T) {
 		})
 
 		t.Run("List", func(t *testing.T) {
-			list, err := client.Customers.ListBankAccounts(testCustomer.ID, &ListRequest{})
+			list, err := client.Customers.ListBankAccounts(testCustomer.ID, &openpay.ListRequest{})
 			if err != nil {
 				t.Error(err)
 			}
@@ -190,15 +190,15 @@ func TestClient(t *testing.T) {
 			}
 		})
 	})
-	
+
 	t.Run("Charges", func(t *testing.T) {
 		// Create test customer
-		testCustomer := &Customer{
+		testCustomer := &openpay.Customer{
 			Name:            "Rick",
 			LastName:        "Sanchez",
 			Email:           "rick@mail.com",
 			RequiresAccount: false,
-			Address: Address{
+			Address: openpay.Address{
 				Line1:       "Calle 6 #910",
 				City:        "Cordoba",
 				State:       "VER",
@@ -213,14 +213,9 @@ func TestClient(t *testing.T) {
 		txid := ""
 
 		// Test card at merchant level
-		card := &Card{
-			HolderName:      fmt.Sprintf("%s %s", testCustomer.Name, testCustomer.LastName),
-			CardNumber:      "4111111111111111",
-			CVV2:            "401",
-			ExpirationMonth: "10",
-			ExpirationYear:  "19",
-			Address:         testCustomer.Address,
-		}
+		card := openpaytest.NewCard()
+		card.HolderName = fmt.Sprintf("%s %s", testCustomer.Name, testCustomer.LastName)
+		card.Address = testCustomer.Address
 
 		t.Run("AddCard", func(t *testing.T) {
 			err := client.Charges.AddCard(card)
@@ -230,8 +225,8 @@ func TestClient(t *testing.T) {
 		})
 
 		t.Run("AtStore", func(t *testing.T) {
-			_, err := client.Charges.AtStore(&ChargeAtStore{
-				Charge: Charge{
+			_, err := client.Charges.AtStore(&openpay.ChargeAtStore{
+				Charge: openpay.Charge{
 					Method:      "store",
 					Amount:      100,
 					Currency:    "MXN",
@@ -246,8 +241,8 @@ func TestClient(t *testing.T) {
 		})
 
 		t.Run("AtBank", func(t *testing.T) {
-			_, err := client.Charges.AtBank(&ChargeAtBank{
-				Charge: Charge{
+			_, err := client.Charges.AtBank(&openpay.ChargeAtBank{
+				Charge: openpay.Charge{
 					Method:      "bank_account",
 					Amount:      100,
 					Currency:    "MXN",
@@ -261,6 +256,25 @@ func TestClient(t *testing.T) {
 			}
 		})
 
+		t.Run("WithCard", func(t *testing.T) {
+			tx, err := client.Charges.WithCard(&openpay.ChargeWithStoredCard{
+				Charge: openpay.Charge{
+					Method:      "card",
+					Amount:      1000,
+					Currency:    "MXN",
+					Description: "sample charge operation",
+					Customer:    *testCustomer,
+				},
+				SourceID:        card.ID,
+				DeviceSessionID: card.DeviceSessionID,
+				Capture:         false,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			txid = tx.ID
+		})
+
 		t.Run("Get", func(t *testing.T) {
 			tx, err := client.Charges.Get(txid)
 			if err != nil {
@@ -272,7 +286,7 @@ func TestClient(t *testing.T) {
 		})
 
 		t.Run("List", func(t *testing.T) {
-			list, err := client.Charges.List(&ChargesListRequest{})
+			list, err := client.Charges.List(&openpay.ChargesListRequest{})
 			if err != nil {
 				t.Error(err)
 			}
@@ -281,25 +295,6 @@ func TestClient(t *testing.T) {
 			}
 		})
 
-		t.Run("WithCard", func(t *testing.T) {
-			tx, err := client.Charges.WithCard(&ChargeWithStoredCard{
-				Charge: Charge{
-					Method:      "card",
-					Amount:      1000,
-					Currency:    "MXN",
-					Description: "sample charge operation",
-					Customer:    *testCustomer,
-				},
-				SourceID:        card.ID,
-				DeviceSessionID: card.DeviceSessionID,
-				Capture:         false,
-			})
-			if err != nil {
-				t.Error(err)
-			}
-			txid = tx.ID
-		})
-
 		t.Run("Capture", func(t *testing.T) {
 			tx, err := client.Charges.Capture(txid, 1000)
 			if err != nil {
@@ -322,13 +317,13 @@ func TestClient(t *testing.T) {
 	})
 
 	t.Run("Webhooks", func(t *testing.T) {
-		hook := &Webhook{
-			User: "foo",
+		hook := &openpay.Webhook{
+			User:     "foo",
 			Password: "bar",
-			URL: "https://hookb.in/voJJ3XXQ",
-			EventTypes: []string{
-				"charge.succeeded",
-				"spei.received",
+			URL:      "https://hookb.in/voJJ3XXQ",
+			EventTypes: []openpay.EventType{
+				openpay.EventChargeSucceeded,
+				openpay.EventSpeiReceived,
 			},
 		}
 
@@ -0,0 +1,94 @@
+// Command openpay-simulator delivers simulated webhook events to a local
+// endpoint, useful when developing webhook handlers.
+//
+//	openpay-simulator -url http://localhost:8080/hooks -event charge.succeeded -amount 250
+//	openpay-simulator -url http://localhost:8080/hooks -event all
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
+)
+
+func main() {
+	var (
+		url      = flag.String("url", "", "destination URL for the events")
+		user     = flag.String("user", "", "username for basic credentials")
+		password = flag.String("password", "", "password for basic credentials")
+		event    = flag.String("event", "charge.succeeded", "event type to deliver, use 'all' for every type")
+		verify   = flag.String("verify", "", "deliver a verification event with the provided code")
+		list     = flag.Bool("list", false, "print the supported event types and exit")
+		dump     = flag.Bool("print", false, "print delivered events")
+		id       = flag.String("id", "", "override the transaction ID")
+		orderID  = flag.String("order-id", "", "override the transaction order ID")
+		customer = flag.String("customer-id", "", "override the transaction customer ID")
+		amount   = flag.Float64("amount", 0, "override the transaction amount")
+		currency = flag.String("currency", "", "override the transaction currency")
+		method   = flag.String("method", "", "override the transaction method")
+		status   = flag.String("status", "", "override the transaction status")
+	)
+	flag.Parse()
+
+	if *list {
+		for _, et := range openpay.AllEvents() {
+			fmt.Println(et)
+		}
+		return
+	}
+	if *url == "" {
+		fmt.Fprintln(os.Stderr, "a destination URL is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	sim := &openpaytest.Simulator{
+		URL:      *url,
+		User:     *user,
+		Password: *password,
+	}
+	if *verify != "" {
+		if err := sim.Verify(*verify); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	overrides := &openpay.Transaction{
+		ID:         *id,
+		OrderID:    *orderID,
+		CustomerID: *customer,
+		Amount:     float32(*amount),
+		Currency:   openpay.Currency(*currency),
+		Method:     openpay.PaymentMethod(*method),
+		Status:     openpay.TransactionStatus(*status),
+	}
+	events := []openpay.EventType{openpay.EventType(*event)}
+	if *event == "all" {
+		events = openpay.AllEvents()
+	}
+
+	failed := false
+	for _, et := range events {
+		ev, err := sim.Send(et, overrides)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		if *dump {
+			b, _ := json.MarshalIndent(ev, "", "  ")
+			fmt.Println(string(b))
+		} else {
+			fmt.Printf("delivered %s (%s)\n", ev.Type, ev.Transaction.ID)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
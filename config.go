@@ -0,0 +1,297 @@
+package openpay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Service locations per country, for sandbox and production environments
+var countryEndpoints = map[string][2]string{
+	"mx": {testAPI, liveAPI},
+	"co": {"https://sandbox-api.openpay.co/", "https://api.openpay.co/"},
+	"pe": {"https://sandbox-api.openpay.pe/", "https://api.openpay.pe/"},
+}
+
+// Config holds the settings required to build a client, usually loaded from
+// environment variables using 'ConfigFromEnv' or from a file using
+// 'LoadConfig'. Settings not provided use the same default values as
+// 'NewClient'.
+//
+// Supported settings, with their file keys and environment variables:
+//
+//	key              OPENPAY_KEY              API key, required
+//	merchant_id      OPENPAY_MERCHANT_ID      merchant identifier, required
+//	production       OPENPAY_PRODUCTION       use the production environment
+//	country          OPENPAY_COUNTRY          mx (default), co or pe
+//	endpoint         OPENPAY_ENDPOINT         alternative service location
+//	api_version      OPENPAY_API_VERSION      API version to use
+//	user_agent       OPENPAY_USER_AGENT       user agent reported
+//	timeout          OPENPAY_TIMEOUT          request timeout, in seconds
+//	keep_alive       OPENPAY_KEEP_ALIVE       connections keep alive, in seconds
+//	max_connections  OPENPAY_MAX_CONNECTIONS  maximum open connections
+type Config struct {
+	Key            string
+	MerchantID     string
+	Production     bool
+	Country        string
+	Endpoint       string
+	APIVersion     string
+	UserAgent      string
+	Timeout        uint
+	KeepAlive      uint
+	MaxConnections uint
+}
+
+// Configuration setting handler
+type setting struct {
+	name string
+	set  func(c *Config, v string) error
+}
+
+// Supported configuration settings
+var settings = []setting{
+	{"key", func(c *Config, v string) error { c.Key = v; return nil }},
+	{"merchant_id", func(c *Config, v string) error { c.MerchantID = v; return nil }},
+	{"production", func(c *Config, v string) (err error) { c.Production, err = strconv.ParseBool(v); return }},
+	{"country", func(c *Config, v string) error { c.Country = strings.ToLower(v); return nil }},
+	{"endpoint", func(c *Config, v string) error { c.Endpoint = v; return nil }},
+	{"api_version", func(c *Config, v string) error { c.APIVersion = v; return nil }},
+	{"user_agent", func(c *Config, v string) error { c.UserAgent = v; return nil }},
+	{"timeout", func(c *Config, v string) error { return parseUint(&c.Timeout, v) }},
+	{"keep_alive", func(c *Config, v string) error { return parseUint(&c.KeepAlive, v) }},
+	{"max_connections", func(c *Config, v string) error { return parseUint(&c.MaxConnections, v) }},
+}
+
+// ConfigFromEnv loads the settings from 'OPENPAY_*' environment variables; all
+// problems found are reported in a single error
+func ConfigFromEnv() (*Config, error) {
+	values := make(map[string]string)
+	for _, s := range settings {
+		if v, ok := os.LookupEnv("OPENPAY_" + strings.ToUpper(s.name)); ok {
+			values[s.name] = v
+		}
+	}
+	return newConfig(values, "environment variable OPENPAY_")
+}
+
+// LoadConfig reads the settings from a JSON or YAML file, the format is
+// detected by the file extension. YAML files must contain a flat list of
+// 'key: value' pairs. All problems found are reported in a single error.
+func LoadConfig(file string) (*Config, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]string
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		values, err = parseJSONConfig(b)
+	case ".yaml", ".yml":
+		values, err = parseYAMLConfig(b)
+	default:
+		return nil, fmt.Errorf("%s: unsupported configuration format", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return newConfig(values, "")
+}
+
+// NewClientFromEnv builds a client using the settings available in 'OPENPAY_*'
+// environment variables
+func NewClientFromEnv() (*Client, error) {
+	c, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return c.Client()
+}
+
+// NewClientFromFile builds a client using the settings in a JSON or YAML file
+func NewClientFromFile(file string) (*Client, error) {
+	c, err := LoadConfig(file)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client()
+}
+
+// Options returns the client configuration options for the settings, using
+// default values for settings not provided
+func (c *Config) Options() (*Options, error) {
+	endpoints, ok := countryEndpoints[c.country()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported country: %s", c.Country)
+	}
+	opts := &Options{
+		Timeout:        c.Timeout,
+		KeepAlive:      c.KeepAlive,
+		MaxConnections: c.MaxConnections,
+		APIVersion:     c.APIVersion,
+		UserAgent:      c.UserAgent,
+		UseProduction:  c.Production,
+		Endpoint:       c.Endpoint,
+	}
+	if opts.Endpoint == "" && c.country() != "mx" {
+		opts.Endpoint = endpoints[0]
+		if c.Production {
+			opts.Endpoint = endpoints[1]
+		}
+	}
+	return opts.withDefaults(), nil
+}
+
+// Client returns a new client instance using the settings
+func (c *Config) Client() (*Client, error) {
+	opts, err := c.Options()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c.Key, c.MerchantID, opts)
+}
+
+func (c *Config) country() string {
+	if c.Country == "" {
+		return "mx"
+	}
+	return c.Country
+}
+
+// Build and validate a configuration from raw values
+func newConfig(values map[string]string, prefix string) (*Config, error) {
+	c := &Config{}
+	var errs []error
+	for _, s := range settings {
+		v, ok := values[s.name]
+		if !ok {
+			continue
+		}
+		name := s.name
+		if prefix != "" {
+			name = prefix + strings.ToUpper(s.name)
+		}
+		if err := s.set(c, strings.TrimSpace(v)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q", name, v))
+		}
+		delete(values, s.name)
+	}
+
+	// Unknown settings
+	unknown := make([]string, 0, len(values))
+	for k := range values {
+		unknown = append(unknown, k)
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		errs = append(errs, fmt.Errorf("unknown setting: %s", k))
+	}
+
+	if c.Key == "" {
+		errs = append(errs, errors.New("API key is required"))
+	}
+	if c.MerchantID == "" {
+		errs = append(errs, errors.New("merchant ID is required"))
+	}
+	if _, ok := countryEndpoints[c.country()]; !ok {
+		errs = append(errs, fmt.Errorf("unsupported country: %s", c.Country))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Read a flat JSON object, values are converted to text
+func parseJSONConfig(b []byte) (map[string]string, error) {
+	raw := make(map[string]interface{})
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v.(type) {
+		case string, bool, json.Number:
+			values[k] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("%s: invalid value", k)
+		}
+	}
+	return values, nil
+}
+
+// Read a flat list of 'key: value' pairs; comments, blank lines and quoted
+// values are supported
+func parseYAMLConfig(b []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || text == "---" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(text, ":")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("line %d: expected 'key: value'", line)
+		}
+		v = stripYAMLComment(strings.TrimSpace(v))
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			if v[0] == '"' {
+				unquoted, err := strconv.Unquote(v)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				v = unquoted
+			} else {
+				v = strings.ReplaceAll(v[1:len(v)-1], "''", "'")
+			}
+		}
+		values[strings.TrimSpace(k)] = v
+	}
+	return values, sc.Err()
+}
+
+// Remove a trailing comment from a YAML value; '#' characters inside quoted
+// values are kept
+func stripYAMLComment(v string) string {
+	var quote byte
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '\'' && c == '\'' && i+1 < len(v) && v[i+1] == '\'':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case i == 0 && (c == '"' || c == '\''):
+			quote = c
+		case c == '#' && (i == 0 || v[i-1] == ' ' || v[i-1] == '\t'):
+			return strings.TrimSpace(v[:i])
+		}
+	}
+	return v
+}
+
+func parseUint(dst *uint, v string) error {
+	n, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return err
+	}
+	*dst = uint(n)
+	return nil
+}
@@ -0,0 +1,106 @@
+package openpay
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("OPENPAY_KEY", "sk_env")
+		t.Setenv("OPENPAY_MERCHANT_ID", "menv")
+		t.Setenv("OPENPAY_COUNTRY", "CO")
+		t.Setenv("OPENPAY_PRODUCTION", "true")
+		t.Setenv("OPENPAY_TIMEOUT", "10")
+		conf, err := ConfigFromEnv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts, _ := conf.Options()
+		if opts.Endpoint != "https://api.openpay.co/" || opts.Timeout != 10 || opts.KeepAlive != 600 || opts.APIVersion != "v1" {
+			t.Errorf("invalid options: %+v", opts)
+		}
+		client, err := conf.Client()
+		if err != nil || client.apiEndpoint != "https://api.openpay.co/" || client.merchantID != "menv" {
+			t.Errorf("invalid client: %v", err)
+		}
+	})
+
+	t.Run("EnvErrors", func(t *testing.T) {
+		t.Setenv("OPENPAY_KEY", "")
+		t.Setenv("OPENPAY_MERCHANT_ID", "")
+		t.Setenv("OPENPAY_COUNTRY", "ar")
+		t.Setenv("OPENPAY_TIMEOUT", "soon")
+		_, err := ConfigFromEnv()
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		for _, msg := range []string{
+			"OPENPAY_TIMEOUT: invalid value",
+			"API key is required",
+			"merchant ID is required",
+			"unsupported country: ar",
+		} {
+			if !strings.Contains(err.Error(), msg) {
+				t.Errorf("missing error %q in: %s", msg, err)
+			}
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "openpay.json")
+		ioutil.WriteFile(file, []byte(`{"key": "sk_json", "merchant_id": "mjson", "max_connections": 5, "production": false}`), 0600)
+		conf, err := LoadConfig(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if conf.Key != "sk_json" || conf.MerchantID != "mjson" || conf.MaxConnections != 5 {
+			t.Errorf("invalid config: %+v", conf)
+		}
+		opts, _ := conf.Options()
+		if opts.Endpoint != "" || opts.UseProduction || opts.Timeout != 30 {
+			t.Errorf("invalid options: %+v", opts)
+		}
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "openpay.yaml")
+		ioutil.WriteFile(file, []byte(`
+# OpenPay settings
+key: "sk_yaml"
+merchant_id: myaml  # sandbox merchant
+user_agent: 'acme ''billing'''
+endpoint: http://localhost:8080
+`), 0600)
+		conf, err := LoadConfig(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if conf.Key != "sk_yaml" || conf.MerchantID != "myaml" || conf.UserAgent != "acme 'billing'" || conf.Endpoint != "http://localhost:8080" {
+			t.Errorf("invalid config: %+v", conf)
+		}
+
+		// Comments after quoted values
+		ioutil.WriteFile(file, []byte(`
+key: "sk_yaml" # test key
+merchant_id: 'myaml'  # sandbox merchant
+user_agent: "acme #1" # quoted '#' is kept
+`), 0600)
+		conf, err = LoadConfig(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if conf.Key != "sk_yaml" || conf.MerchantID != "myaml" || conf.UserAgent != "acme #1" {
+			t.Errorf("invalid config: %+v", conf)
+		}
+
+		ioutil.WriteFile(file, []byte("key: sk\nmerchant: m\ntimeout: -1\n"), 0600)
+		_, err = LoadConfig(file)
+		if err == nil || !strings.Contains(err.Error(), "unknown setting: merchant") ||
+			!strings.Contains(err.Error(), "timeout: invalid value") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
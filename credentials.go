@@ -0,0 +1,132 @@
+package openpay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider supplies the API key used to authenticate requests; it
+// is consulted on every request, allowing keys to be rotated without
+// restarting the client. When the service rejects a key the provider is
+// expired and the request retried once. Implementations must be safe for
+// concurrent use.
+type CredentialsProvider interface {
+	// Key returns the API key to use
+	Key(ctx context.Context) (string, error)
+
+	// Expire discards any cached value, called when the service rejects the
+	// key in use
+	Expire()
+}
+
+// StaticKey is a credentials provider that always returns the same key
+type StaticKey string
+
+// Key implements 'CredentialsProvider'
+func (k StaticKey) Key(ctx context.Context) (string, error) {
+	return string(k), nil
+}
+
+// Expire implements 'CredentialsProvider', static keys never change
+func (k StaticKey) Expire() {}
+
+// EnvCredentials reads the API key from an environment variable on every
+// request
+type EnvCredentials struct {
+	// Variable name, for example 'OPENPAY_KEY'
+	Variable string
+}
+
+// Key implements 'CredentialsProvider'
+func (e *EnvCredentials) Key(ctx context.Context) (string, error) {
+	key := strings.TrimSpace(os.Getenv(e.Variable))
+	if key == "" {
+		return "", fmt.Errorf("environment variable %s is not set", e.Variable)
+	}
+	return key, nil
+}
+
+// Expire implements 'CredentialsProvider'
+func (e *EnvCredentials) Expire() {}
+
+// FileCredentials reads the API key from a file on every request, for example
+// a secret mounted by a secrets manager; surrounding whitespace is ignored
+type FileCredentials struct {
+	Path string
+}
+
+// Key implements 'CredentialsProvider'
+func (f *FileCredentials) Key(ctx context.Context) (string, error) {
+	b, err := ioutil.ReadFile(f.Path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return "", fmt.Errorf("credentials file %s is empty", f.Path)
+	}
+	return key, nil
+}
+
+// Expire implements 'CredentialsProvider'
+func (f *FileCredentials) Expire() {}
+
+// CachedCredentials keeps the key returned by another provider, to avoid
+// consulting it on every request. The key is refreshed when the TTL elapses or
+// the service rejects it.
+type CachedCredentials struct {
+	source  CredentialsProvider
+	ttl     time.Duration
+	mu      sync.Mutex
+	key     string
+	expires time.Time
+}
+
+// NewCachedCredentials returns a provider caching the keys returned by
+// 'source' for the provided TTL; if TTL is 0 keys are kept until rejected
+// by the service
+func NewCachedCredentials(source CredentialsProvider, ttl time.Duration) *CachedCredentials {
+	return &CachedCredentials{source: source, ttl: ttl}
+}
+
+// Key implements 'CredentialsProvider'
+func (c *CachedCredentials) Key(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.key != "" && (c.ttl == 0 || time.Now().Before(c.expires)) {
+		return c.key, nil
+	}
+	key, err := c.source.Key(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.key = key
+	c.expires = time.Now().Add(c.ttl)
+	return key, nil
+}
+
+// Expire implements 'CredentialsProvider', the source provider is expired as
+// well
+func (c *CachedCredentials) Expire() {
+	c.mu.Lock()
+	c.key = ""
+	c.mu.Unlock()
+	c.source.Expire()
+}
+
+// Determine the provider to use for a new client
+func credentialsFor(key string, options *Options) (CredentialsProvider, error) {
+	if options.Credentials != nil {
+		return options.Credentials, nil
+	}
+	if key == "" {
+		return nil, errors.New("API key is required")
+	}
+	return StaticKey(key), nil
+}
@@ -0,0 +1,83 @@
+package openpay_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
+)
+
+func TestCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Env", func(t *testing.T) {
+		creds := &openpay.EnvCredentials{Variable: "OPENPAY_TEST_KEY"}
+		os.Unsetenv("OPENPAY_TEST_KEY")
+		if _, err := creds.Key(ctx); err == nil {
+			t.Error("expected error for missing variable")
+		}
+		t.Setenv("OPENPAY_TEST_KEY", "sk_env")
+		if key, _ := creds.Key(ctx); key != "sk_env" {
+			t.Errorf("unexpected key: %s", key)
+		}
+	})
+
+	t.Run("Cached", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "key")
+		creds := openpay.NewCachedCredentials(&openpay.FileCredentials{Path: file}, 0)
+		if _, err := creds.Key(ctx); err == nil {
+			t.Error("expected error for missing file")
+		}
+		ioutil.WriteFile(file, []byte("sk_first\n"), 0600)
+		if key, _ := creds.Key(ctx); key != "sk_first" {
+			t.Errorf("unexpected key: %s", key)
+		}
+		ioutil.WriteFile(file, []byte("sk_second"), 0600)
+		if key, _ := creds.Key(ctx); key != "sk_first" {
+			t.Errorf("key should be cached: %s", key)
+		}
+		creds.Expire()
+		if key, _ := creds.Key(ctx); key != "sk_second" {
+			t.Errorf("key should be refreshed: %s", key)
+		}
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		srv := openpaytest.NewServer()
+		defer srv.Close()
+
+		file := filepath.Join(t.TempDir(), "key")
+		ioutil.WriteFile(file, []byte(srv.Key), 0600)
+		opts := srv.Options()
+		opts.Credentials = openpay.NewCachedCredentials(&openpay.FileCredentials{Path: file}, 0)
+		client, err := openpay.NewClient("", srv.MerchantID, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Webhooks.List(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Rejected keys are refreshed and the request retried
+		srv.SetKey("sk_test_rotated")
+		ioutil.WriteFile(file, []byte("sk_test_rotated"), 0600)
+		meta := openpay.Response{}
+		if _, err := client.WithResponse(&meta).Webhooks.List(); err != nil {
+			t.Fatal(err)
+		}
+		if meta.Attempts != 2 {
+			t.Errorf("expected 2 attempts, got %d", meta.Attempts)
+		}
+
+		// Retried only once
+		srv.SetKey("sk_test_other")
+		_, err = client.WithResponse(&meta).Webhooks.List()
+		if e, ok := err.(*openpay.APIError); !ok || e.HTTPCode != 401 || meta.Attempts != 2 {
+			t.Errorf("unexpected result: %v, %d attempts", err, meta.Attempts)
+		}
+	})
+}
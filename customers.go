@@ -53,174 +53,211 @@ type customersClient struct {
 }
 
 func (cu *customersClient) Create(customer *Customer) error {
+	if err := customer.Validate(); err != nil {
+		return err
+	}
+
 	b, err := cu.c.request(&requestOptions{
-		endpoint: "customers",
-		method:   http.MethodPost,
-		data:     customer,
+		operation: "customers.create",
+		endpoint:  "customers",
+		method:    http.MethodPost,
+		data:      customer,
 	})
 	if err != nil {
 		return err
 	}
 
-	json.Unmarshal(b, customer)
-	return nil
+	return json.Unmarshal(b, customer)
 }
 
 func (cu *customersClient) Update(customer *Customer) error {
 	b, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customer.ID),
-		method:   http.MethodPut,
-		data:     customer,
+		operation: "customers.update",
+		endpoint:  path.Join("customers", customer.ID),
+		method:    http.MethodPut,
+		data:      customer,
 	})
 	if err != nil {
 		return err
 	}
 
-	json.Unmarshal(b, customer)
-	return nil
+	return json.Unmarshal(b, customer)
 }
 
 func (cu *customersClient) Get(customerID string) (*Customer, error) {
 	b, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customerID),
-		method:   http.MethodGet,
-		data:     nil,
+		operation: "customers.get",
+		endpoint:  path.Join("customers", customerID),
+		method:    http.MethodGet,
+		data:      nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	c := &Customer{}
-	json.Unmarshal(b, c)
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
 func (cu *customersClient) List(req *CustomersListRequest) ([]Customer, error) {
 	b, err := cu.c.request(&requestOptions{
-		endpoint: "customers",
-		method:   http.MethodGet,
-		data:     req,
+		operation: "customers.list",
+		endpoint:  "customers",
+		method:    http.MethodGet,
+		data:      req,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	var list []Customer
-	json.Unmarshal(b, &list)
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, err
+	}
 	return list, nil
 }
 
 func (cu *customersClient) Delete(customerID string) error {
 	_, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customerID),
-		method:   http.MethodDelete,
-		data:     nil,
+		operation: "customers.delete",
+		endpoint:  path.Join("customers", customerID),
+		method:    http.MethodDelete,
+		data:      nil,
 	})
 	return err
 }
 
 func (cu *customersClient) AddCard(customerID string, card *Card) error {
+	if err := card.Validate(); err != nil {
+		return err
+	}
+	if err := cu.c.checkCard(card); err != nil {
+		return err
+	}
+
 	b, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customerID, "cards"),
-		method:   http.MethodPost,
-		data:     card,
+		operation: "customers.cards.create",
+		endpoint:  path.Join("customers", customerID, "cards"),
+		method:    http.MethodPost,
+		data:      card,
 	})
 	if err != nil {
 		return err
 	}
 
-	json.Unmarshal(b, card)
-	return nil
+	return json.Unmarshal(b, card)
 }
 
 func (cu *customersClient) GetCard(customerID, cardID string) (*Card, error) {
 	b, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customerID, "cards", cardID),
-		method:   http.MethodGet,
-		data:     nil,
+		operation: "customers.cards.get",
+		endpoint:  path.Join("customers", customerID, "cards", cardID),
+		method:    http.MethodGet,
+		data:      nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	c := &Card{}
-	json.Unmarshal(b, c)
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
 func (cu *customersClient) ListCards(customerID string, req *ListRequest) ([]Card, error) {
 	b, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customerID, "cards"),
-		method:   http.MethodGet,
-		data:     req,
+		operation: "customers.cards.list",
+		endpoint:  path.Join("customers", customerID, "cards"),
+		method:    http.MethodGet,
+		data:      req,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	var list []Card
-	json.Unmarshal(b, &list)
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, err
+	}
 	return list, nil
 }
 
 func (cu *customersClient) DeleteCard(customerID, cardID string) error {
 	_, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customerID, "cards", cardID),
-		method:   http.MethodDelete,
-		data:     nil,
+		operation: "customers.cards.delete",
+		endpoint:  path.Join("customers", customerID, "cards", cardID),
+		method:    http.MethodDelete,
+		data:      nil,
 	})
 	return err
 }
 
 func (cu *customersClient) AddBankAccount(customerID string, acc *BankAccount) error {
+	if err := acc.Validate(); err != nil {
+		return err
+	}
+	acc.FillBankDetails()
+
 	b, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customerID, "bankaccounts"),
-		method:   http.MethodPost,
-		data:     acc,
+		operation: "customers.bank_accounts.create",
+		endpoint:  path.Join("customers", customerID, "bankaccounts"),
+		method:    http.MethodPost,
+		data:      acc,
 	})
 	if err != nil {
 		return err
 	}
 
-	json.Unmarshal(b, acc)
-	return nil
+	return json.Unmarshal(b, acc)
 }
 
 func (cu *customersClient) GetBankAccount(customerID, accountID string) (*BankAccount, error) {
 	b, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customerID, "bankaccounts", accountID),
-		method:   http.MethodGet,
-		data:     nil,
+		operation: "customers.bank_accounts.get",
+		endpoint:  path.Join("customers", customerID, "bankaccounts", accountID),
+		method:    http.MethodGet,
+		data:      nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	acc := &BankAccount{}
-	json.Unmarshal(b, acc)
+	if err := json.Unmarshal(b, acc); err != nil {
+		return nil, err
+	}
 	return acc, nil
 }
 
 func (cu *customersClient) ListBankAccounts(customerID string, req *ListRequest) ([]BankAccount, error) {
 	b, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customerID, "bankaccounts"),
-		method:   http.MethodGet,
-		data:     req,
+		operation: "customers.bank_accounts.list",
+		endpoint:  path.Join("customers", customerID, "bankaccounts"),
+		method:    http.MethodGet,
+		data:      req,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	var list []BankAccount
-	json.Unmarshal(b, &list)
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, err
+	}
 	return list, nil
 }
 
 func (cu *customersClient) DeleteBankAccount(customerID, accountID string) error {
 	_, err := cu.c.request(&requestOptions{
-		endpoint: path.Join("customers", customerID, "bankaccounts", accountID),
-		method:   http.MethodDelete,
-		data:     nil,
+		operation: "customers.bank_accounts.delete",
+		endpoint:  path.Join("customers", customerID, "bankaccounts", accountID),
+		method:    http.MethodDelete,
+		data:      nil,
 	})
 	return err
 }
@@ -6,13 +6,13 @@ import "time"
 // https://www.openpay.mx/docs/api/#cargos
 type Charge struct {
 	// Valid values are: card, store, bank_account
-	Method string `json:"method,omitempty"`
+	Method PaymentMethod `json:"method,omitempty"`
 
 	// Amount to charge, with up to two decimal digits
 	Amount float32 `json:"amount,omitempty"`
 
 	// Valid values: MXN or USD
-	Currency string `json:"currency,omitempty"`
+	Currency Currency `json:"currency,omitempty"`
 
 	// Basic description for the origin of the charge
 	Description string `json:"description,omitempty"`
@@ -59,7 +59,7 @@ type ChargeWithStoredCard struct {
 	Capture bool `json:"capture"`
 
 	// Use loyalty points for payment, valid values are: ONLY_POINTS, MIXED, NONE
-	UseCardPoints string `json:"use_card_points,omitempty"`
+	UseCardPoints CardPointsUsage `json:"use_card_points,omitempty"`
 
 	// Specify if 3d secure should be used
 	Use3DSecure bool `json:"use_3d_secure"`
@@ -99,10 +99,10 @@ type Transaction struct {
 	Authorization string `json:"authorization,omitempty"`
 
 	// Valid values are: fee, charge, payout, transfer
-	TransactionType string `json:"transaction_type,omitempty"`
+	TransactionType TransactionType `json:"transaction_type,omitempty"`
 
 	// Affectation to the account: in, out
-	OperationType string `json:"operation_type,omitempty"`
+	OperationType OperationType `json:"operation_type,omitempty"`
 
 	// Unique identifier for the order, unique for all transactions
 	OrderID string `json:"order_id,omitempty"`
@@ -114,17 +114,20 @@ type Transaction struct {
 	Amount float32 `json:"amount,omitempty"`
 
 	// Valid values: MXN or USD
-	Currency string `json:"currency,omitempty"`
+	Currency Currency `json:"currency,omitempty"`
 
 	// Used method when executing the transaction
-	Method string `json:"method,omitempty"`
+	Method PaymentMethod `json:"method,omitempty"`
 
 	// UTC in ISO 8601 format
 	CreationDate time.Time `json:"creation_date,omitempty"`
 
+	// Payment deadline for store and bank charges, UTC in ISO 8601 format
+	DueDate time.Time `json:"due_date,omitempty"`
+
 	// Current transaction status: completed, in_progress, failed
 	// https://www.openpay.mx/docs/api/#objeto-transaction-status
-	Status string `json:"status,omitempty"`
+	Status TransactionStatus `json:"status,omitempty"`
 
 	// Set on 'failed' transactions
 	ErrorMessage string `json:"error_message,omitempty"`
@@ -263,7 +266,7 @@ type Customer struct {
 	PhoneNumber string `json:"phone_number,omitempty"`
 
 	// Current customer registry status, valid values are: active, deleted
-	Status string `json:"status,omitempty"`
+	Status CustomerStatus `json:"status,omitempty"`
 
 	// Current customer balance, up to two decimal digits
 	Balance float32 `json:"balance"`
@@ -315,10 +318,10 @@ type Card struct {
 	PointsCard bool `json:"points_card"`
 
 	// Available options are: visa, mastercard, carnet o american express
-	Brand string `json:"brand,omitempty"`
+	Brand CardBrand `json:"brand,omitempty"`
 
 	// Available options are: debit, credit, cash
-	Type string `json:"type,omitempty"`
+	Type CardType `json:"type,omitempty"`
 
 	// Issuer bank name
 	BankName string `json:"bank_name,omitempty"`
@@ -397,10 +400,10 @@ type ChargesListRequest struct {
 	// Amount lower range limit
 	AmountLte string `json:"amount[lte],omitempty"`
 
-	// Valid values are:
-	// IN_PROGRESS, COMPLETED, REFUNDED, CHARGEBACK_PENDING, CHARGEBACK_ACCEPTED,
-	// CHARGEBACK_ADJUSTMENT, CHARGE_PENDING, CANCELLED, FAILED
-	Status string `json:"status,omitempty"`
+	// Filter by status, using any of the supported values like
+	// 'StatusCompleted'; the format expected by the service, for example
+	// COMPLETED, is used when sending the request
+	Status TransactionStatus `json:"status,omitempty"`
 
 	// Charges related to a specific order
 	OrderID string `json:"order_id,omitempty"`
@@ -424,30 +427,53 @@ type Webhook struct {
 	// Current status of the instance, can be 'verified' or 'unverified'
 	Status string `json:"status,omitempty"`
 
-	// Valid events to be delivered for the instance
-	// charge.refunded
-	// charge.failed
-	// charge.cancelled
-	// charge.created
-	// charge.succeeded
-	// charge.rescored.to.decline
-	// subscription.charge.failed
-	// payout.created
-	// payout.succeeded
-	// payout.failed
-	// transfer.succeeded
-	// fee.succeeded
-	// fee.refund.succeeded
-	// spei.received
-	// chargeback.created
-	// chargeback.rejected
-	// chargeback.accepted
-	// order.created
-	// order.activated
-	// order.payment.received
-	// order.completed
-	// order.expired
-	// order.cancelled
-	// order.payment.cancelled
-	EventTypes []string `json:"event_types,omitemtpy"`
-}
\ No newline at end of file
+	// Events to be delivered to the instance, see 'AllEvents' for supported values
+	EventTypes []EventType `json:"event_types,omitempty"`
+}
+
+// Notification delivered to registered webhooks
+// https://www.openpay.mx/docs/api/#webhooks
+type Event struct {
+	// Kind of event
+	Type EventType `json:"type,omitempty"`
+
+	// UTC in ISO 8601 format
+	EventDate time.Time `json:"event_date,omitempty"`
+
+	// Transaction that triggered the event, if any
+	Transaction *Transaction `json:"transaction,omitempty"`
+
+	// Set when verifying a newly registered webhook
+	VerificationCode string `json:"verification_code,omitempty"`
+}
+
+// Merchant account information
+// https://www.openpay.mx/docs/api/#comercios
+type Merchant struct {
+	// Unique identifier
+	ID string `json:"id,omitempty"`
+
+	// Commercial name
+	Name string `json:"name,omitempty"`
+
+	// Contact email address
+	Email string `json:"email,omitempty"`
+
+	// Contact phone number
+	Phone string `json:"phone,omitempty"`
+
+	// Current account status
+	Status string `json:"status,omitempty"`
+
+	// Registration date in UTC and ISO 8601 format
+	CreationDate time.Time `json:"creation_date,omitempty"`
+
+	// Current account balance
+	Balance float32 `json:"balance"`
+
+	// Funds available for withdrawal
+	AvailableFunds float32 `json:"available_funds"`
+
+	// Special code to operate transactions with any bank in Mexico
+	Clabe string `json:"clabe,omitempty"`
+}
@@ -0,0 +1,89 @@
+package openpay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	dry := NewDryRun()
+	client, _ := NewClient("sk_dry", "mdry", &Options{DryRun: dry})
+
+	card := testCard()
+	if err := client.Customers.AddCard("c1", card); err != nil {
+		t.Fatal(err)
+	}
+	if card.ID == "" || card.CardNumber != "411111XXXXXX1111" || card.CVV2 != "401" || card.Brand != BrandVisa {
+		t.Errorf("invalid synthetic card: %+v", card)
+	}
+
+	tx, err := client.Charges.WithCard(&ChargeWithStoredCard{
+		Charge:   Charge{Method: MethodCard, Amount: 100, Currency: CurrencyMXN, OrderID: "o1"},
+		SourceID: card.ID,
+		CVV2:     "401",
+		Capture:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.ID == "" || tx.Status != StatusCompleted || tx.Amount != 100 || tx.OrderID != "o1" {
+		t.Errorf("invalid synthetic transaction: %+v", tx)
+	}
+	tx, _ = client.Charges.AtStore(&ChargeAtStore{Charge: Charge{Method: MethodStore, Amount: 50}})
+	if tx.Status != StatusInProgress {
+		t.Errorf("invalid synthetic transaction: %+v", tx)
+	}
+	tx, _ = client.Charges.Refund("tx1", 0, "")
+	if tx.ID != "tx1" || tx.Status != StatusRefunded {
+		t.Errorf("invalid synthetic transaction: %+v", tx)
+	}
+	if list, err := client.Webhooks.List(); err != nil || len(list) != 0 {
+		t.Errorf("unexpected list: %v, %v", list, err)
+	}
+	if err := client.Webhooks.Delete("wh1"); err != nil {
+		t.Error(err)
+	}
+
+	reqs := dry.Requests()
+	if len(reqs) != 6 {
+		t.Fatalf("expected 6 requests, got %d", len(reqs))
+	}
+	first := reqs[0]
+	if first.Operation != "customers.cards.create" || first.Method != "POST" ||
+		first.URL != testAPI+"v1/mdry/customers/c1/cards" || first.Header.Get("Authorization") != "" {
+		t.Errorf("invalid request: %+v", first)
+	}
+	if strings.Contains(first.Body, "4111111111111111") || strings.Contains(first.Body, `"401"`) {
+		t.Errorf("sensitive data captured: %s", first.Body)
+	}
+	if reqs[5].Method != "DELETE" || !strings.HasSuffix(reqs[5].URL, "/webhooks/wh1") {
+		t.Errorf("invalid request: %+v", reqs[5])
+	}
+	dry.Reset()
+	if len(dry.Requests()) != 0 {
+		t.Error("requests should be discarded")
+	}
+}
+
+func TestDryRunBypass(t *testing.T) {
+	started := 0
+	client, _ := NewClient("sk_dry", "mdry", &Options{
+		DryRun:         NewDryRun(),
+		Credentials:    &EnvCredentials{Variable: "OPENPAY_DRY_RUN_UNSET"},
+		RateLimits:     &RateLimits{Reads: RateLimit{Rate: 0.001, Burst: 1}},
+		CircuitBreaker: &CircuitBreaker{Threshold: 1},
+		Observers: []Observer{ObserverFuncs{
+			OnStart: func(info *RequestInfo) { started++ },
+		}},
+	})
+
+	// Dry runs are neither observed nor limited, and don't need credentials
+	for n := 0; n < 3; n++ {
+		if _, err := client.Webhooks.List(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if started != 0 {
+		t.Errorf("dry runs should not be observed: %d", started)
+	}
+}
@@ -0,0 +1,166 @@
+package openpay
+
+// Values defined by the service for enumerated fields. Unknown values, for
+// example introduced by newer versions of the service, are preserved when
+// decoding responses and can be detected using the 'Valid' methods.
+
+// Method used to execute a transaction
+type PaymentMethod string
+
+// Supported payment methods
+const (
+	MethodCard        PaymentMethod = "card"
+	MethodStore       PaymentMethod = "store"
+	MethodBankAccount PaymentMethod = "bank_account"
+
+	// Used on transfers and fees operated directly on customer accounts
+	MethodCustomer PaymentMethod = "customer"
+)
+
+// Valid returns true for values known by the client
+func (v PaymentMethod) Valid() bool {
+	switch v {
+	case MethodCard, MethodStore, MethodBankAccount, MethodCustomer:
+		return true
+	}
+	return false
+}
+
+// Currency code in ISO 4217 format
+type Currency string
+
+// Supported currencies
+const (
+	CurrencyMXN Currency = "MXN"
+	CurrencyUSD Currency = "USD"
+)
+
+// Valid returns true for values known by the client
+func (v Currency) Valid() bool {
+	return v == CurrencyMXN || v == CurrencyUSD
+}
+
+// Current status of a transaction
+// https://www.openpay.mx/docs/api/#objeto-transaction-status
+type TransactionStatus string
+
+// Supported transaction status values
+const (
+	StatusInProgress           TransactionStatus = "in_progress"
+	StatusCompleted            TransactionStatus = "completed"
+	StatusFailed               TransactionStatus = "failed"
+	StatusCancelled            TransactionStatus = "cancelled"
+	StatusRefunded             TransactionStatus = "refunded"
+	StatusChargePending        TransactionStatus = "charge_pending"
+	StatusChargebackPending    TransactionStatus = "chargeback_pending"
+	StatusChargebackAccepted   TransactionStatus = "chargeback_accepted"
+	StatusChargebackAdjustment TransactionStatus = "chargeback_adjustment"
+)
+
+// Valid returns true for values known by the client
+func (v TransactionStatus) Valid() bool {
+	switch v {
+	case StatusInProgress, StatusCompleted, StatusFailed, StatusCancelled, StatusRefunded,
+		StatusChargePending, StatusChargebackPending, StatusChargebackAccepted, StatusChargebackAdjustment:
+		return true
+	}
+	return false
+}
+
+// Kind of transaction
+type TransactionType string
+
+// Supported transaction types
+const (
+	TransactionFee      TransactionType = "fee"
+	TransactionCharge   TransactionType = "charge"
+	TransactionPayout   TransactionType = "payout"
+	TransactionTransfer TransactionType = "transfer"
+)
+
+// Valid returns true for values known by the client
+func (v TransactionType) Valid() bool {
+	switch v {
+	case TransactionFee, TransactionCharge, TransactionPayout, TransactionTransfer:
+		return true
+	}
+	return false
+}
+
+// Affectation of a transaction to the account
+type OperationType string
+
+// Supported operation types
+const (
+	OperationIn  OperationType = "in"
+	OperationOut OperationType = "out"
+)
+
+// Valid returns true for values known by the client
+func (v OperationType) Valid() bool {
+	return v == OperationIn || v == OperationOut
+}
+
+// Card brand
+type CardBrand string
+
+// Supported card brands
+const (
+	BrandVisa            CardBrand = "visa"
+	BrandMastercard      CardBrand = "mastercard"
+	BrandCarnet          CardBrand = "carnet"
+	BrandAmericanExpress CardBrand = "american_express"
+)
+
+// Valid returns true for values known by the client
+func (v CardBrand) Valid() bool {
+	switch v {
+	case BrandVisa, BrandMastercard, BrandCarnet, BrandAmericanExpress:
+		return true
+	}
+	return false
+}
+
+// Kind of card
+type CardType string
+
+// Supported card types
+const (
+	CardDebit  CardType = "debit"
+	CardCredit CardType = "credit"
+	CardCash   CardType = "cash"
+)
+
+// Valid returns true for values known by the client
+func (v CardType) Valid() bool {
+	return v == CardDebit || v == CardCredit || v == CardCash
+}
+
+// Use of loyalty points when charging a card
+type CardPointsUsage string
+
+// Supported card points usage values
+const (
+	PointsOnly  CardPointsUsage = "ONLY_POINTS"
+	PointsMixed CardPointsUsage = "MIXED"
+	PointsNone  CardPointsUsage = "NONE"
+)
+
+// Valid returns true for values known by the client
+func (v CardPointsUsage) Valid() bool {
+	return v == PointsOnly || v == PointsMixed || v == PointsNone
+}
+
+// Customer registry status
+type CustomerStatus string
+
+// Supported customer status values
+const (
+	CustomerActive  CustomerStatus = "active"
+	CustomerDeleted CustomerStatus = "deleted"
+)
+
+// Valid returns true for values known by the client
+func (v CustomerStatus) Valid() bool {
+	return v == CustomerActive || v == CustomerDeleted
+}
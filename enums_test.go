@@ -0,0 +1,47 @@
+package openpay
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEnums(t *testing.T) {
+	tx := &Transaction{}
+	err := json.Unmarshal([]byte(`{"method":"card","currency":"MXN","status":"completed","transaction_type":"charge","operation_type":"in","card":{"brand":"visa","type":"credit"}}`), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Method != MethodCard || tx.Currency != CurrencyMXN || tx.Status != StatusCompleted ||
+		tx.TransactionType != TransactionCharge || tx.OperationType != OperationIn ||
+		tx.Card.Brand != BrandVisa || tx.Card.Type != CardCredit {
+		t.Errorf("invalid data received: %+v", tx)
+	}
+	for _, ok := range []bool{tx.Method.Valid(), tx.Currency.Valid(), tx.Status.Valid(), tx.Card.Brand.Valid()} {
+		if !ok {
+			t.Error("known value reported as invalid")
+		}
+	}
+
+	// Unknown values are preserved
+	if err := json.Unmarshal([]byte(`{"status":"on_hold","method":"wallet"}`), tx); err != nil {
+		t.Fatal(err)
+	}
+	if tx.Status != "on_hold" || tx.Status.Valid() || tx.Method.Valid() {
+		t.Errorf("unexpected result: %+v", tx)
+	}
+	b, _ := json.Marshal(&Charge{Method: MethodStore, Currency: CurrencyUSD})
+	if !strings.HasPrefix(string(b), `{"method":"store","currency":"USD",`) {
+		t.Errorf("unexpected encoding: %s", b)
+	}
+
+	// Invalid values are rejected locally
+	sale := &ChargeWithStoredCard{
+		Charge:        Charge{Method: MethodCard, Amount: 10, Currency: "EUR"},
+		SourceID:      "card-id",
+		UseCardPoints: "SOME",
+	}
+	if err, _ := sale.Validate().(*ValidationError); err == nil || !err.Has("currency") || !err.Has("use_card_points") {
+		t.Errorf("unexpected result: %v", err)
+	}
+}
@@ -29,3 +29,19 @@ type APIError struct {
 func (e *APIError) Error() string {
 	return fmt.Sprintf("%d: %s - %s", e.Code, e.Category, e.Description)
 }
+
+// Error codes reported when operating with cards
+// https://www.openpay.mx/docs/api/#c-digos-de-error
+const (
+	ErrCodeCardDeclined      uint = 3001
+	ErrCodeCardExpired       uint = 3002
+	ErrCodeInsufficientFunds uint = 3003
+	ErrCodeCardStolen        uint = 3004
+	ErrCodeFraudRejected     uint = 3005
+	ErrCodeNotAllowed        uint = 3006
+	ErrCodeCardNotSupported  uint = 3008
+	ErrCodeCardLost          uint = 3009
+	ErrCodeCardRestricted    uint = 3010
+	ErrCodeCardRetained      uint = 3011
+	ErrCodeAuthRequired      uint = 3012
+)
@@ -0,0 +1,134 @@
+package openpay
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind of notification delivered to registered webhooks
+// https://www.openpay.mx/docs/api/#webhooks
+type EventType string
+
+// Supported event types
+const (
+	EventChargeRefunded           EventType = "charge.refunded"
+	EventChargeFailed             EventType = "charge.failed"
+	EventChargeCancelled          EventType = "charge.cancelled"
+	EventChargeCreated            EventType = "charge.created"
+	EventChargeSucceeded          EventType = "charge.succeeded"
+	EventChargeRescoredToDecline  EventType = "charge.rescored.to.decline"
+	EventSubscriptionChargeFailed EventType = "subscription.charge.failed"
+	EventPayoutCreated            EventType = "payout.created"
+	EventPayoutSucceeded          EventType = "payout.succeeded"
+	EventPayoutFailed             EventType = "payout.failed"
+	EventTransferSucceeded        EventType = "transfer.succeeded"
+	EventFeeSucceeded             EventType = "fee.succeeded"
+	EventFeeRefundSucceeded       EventType = "fee.refund.succeeded"
+	EventSpeiReceived             EventType = "spei.received"
+	EventChargebackCreated        EventType = "chargeback.created"
+	EventChargebackRejected       EventType = "chargeback.rejected"
+	EventChargebackAccepted       EventType = "chargeback.accepted"
+	EventOrderCreated             EventType = "order.created"
+	EventOrderActivated           EventType = "order.activated"
+	EventOrderPaymentReceived     EventType = "order.payment.received"
+	EventOrderCompleted           EventType = "order.completed"
+	EventOrderExpired             EventType = "order.expired"
+	EventOrderCancelled           EventType = "order.cancelled"
+	EventOrderPaymentCancelled    EventType = "order.payment.cancelled"
+)
+
+// Sent by the service to confirm a newly registered webhook, can't be
+// subscribed to
+const EventVerification EventType = "verification"
+
+// Complete list of event types webhooks can subscribe to
+var allEvents = []EventType{
+	EventChargeRefunded,
+	EventChargeFailed,
+	EventChargeCancelled,
+	EventChargeCreated,
+	EventChargeSucceeded,
+	EventChargeRescoredToDecline,
+	EventSubscriptionChargeFailed,
+	EventPayoutCreated,
+	EventPayoutSucceeded,
+	EventPayoutFailed,
+	EventTransferSucceeded,
+	EventFeeSucceeded,
+	EventFeeRefundSucceeded,
+	EventSpeiReceived,
+	EventChargebackCreated,
+	EventChargebackRejected,
+	EventChargebackAccepted,
+	EventOrderCreated,
+	EventOrderActivated,
+	EventOrderPaymentReceived,
+	EventOrderCompleted,
+	EventOrderExpired,
+	EventOrderCancelled,
+	EventOrderPaymentCancelled,
+}
+
+// AllEvents returns every event type webhooks can subscribe to
+func AllEvents() []EventType {
+	return append([]EventType(nil), allEvents...)
+}
+
+// ChargeEvents returns all 'charge.*' event types
+func ChargeEvents() []EventType {
+	return eventsWithPrefix("charge.")
+}
+
+// ChargebackEvents returns all 'chargeback.*' event types
+func ChargebackEvents() []EventType {
+	return eventsWithPrefix("chargeback.")
+}
+
+// PayoutEvents returns all 'payout.*' event types
+func PayoutEvents() []EventType {
+	return eventsWithPrefix("payout.")
+}
+
+// FeeEvents returns all 'fee.*' event types
+func FeeEvents() []EventType {
+	return eventsWithPrefix("fee.")
+}
+
+// OrderEvents returns all 'order.*' event types
+func OrderEvents() []EventType {
+	return eventsWithPrefix("order.")
+}
+
+// Valid returns true for event types webhooks can subscribe to
+func (et EventType) Valid() bool {
+	for _, e := range allEvents {
+		if e == et {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns an error listing any unsupported event types
+func validateEventTypes(list []EventType) error {
+	var invalid []string
+	for _, et := range list {
+		if !et.Valid() {
+			invalid = append(invalid, fmt.Sprintf("%q", et))
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid event types: %s", strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+func eventsWithPrefix(prefix string) []EventType {
+	var list []EventType
+	for _, e := range allEvents {
+		if strings.HasPrefix(string(e), prefix) {
+			list = append(list, e)
+		}
+	}
+	return list
+}
@@ -0,0 +1,27 @@
+package openpay
+
+import "testing"
+
+func TestEventTypes(t *testing.T) {
+	for _, et := range AllEvents() {
+		if !et.Valid() {
+			t.Errorf("%s should be valid", et)
+		}
+	}
+	if EventVerification.Valid() || EventType("charge.unknown").Valid() {
+		t.Error("failed to detect invalid event type")
+	}
+	if len(ChargeEvents()) != 6 || len(ChargebackEvents()) != 3 {
+		t.Error("invalid event groups")
+	}
+
+	// Unknown events must be rejected before reaching the service
+	client, _ := NewClient("sk_invalid", "invalid", nil)
+	err := client.Webhooks.Create(&Webhook{
+		URL:        "https://example.com/hooks",
+		EventTypes: []EventType{EventChargeSucceeded, "charge.unknown"},
+	})
+	if err == nil || err.Error() != `invalid event types: "charge.unknown"` {
+		t.Errorf("unexpected result: %v", err)
+	}
+}
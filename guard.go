@@ -0,0 +1,125 @@
+package openpay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrReadOnly is returned, without reaching the service, for requests
+// modifying data on clients using the 'ReadOnly' option
+var ErrReadOnly = errors.New("request not allowed on read-only client")
+
+// ErrNotConfigured is returned, without reaching the service, by clients not
+// created with 'NewClient', for example the ones backed by mocks, for requests
+// not handled by their API implementations
+var ErrNotConfigured = errors.New("client not configured, use 'NewClient' to create it")
+
+// ErrSandboxCard is returned when trying to register one of the sandbox test
+// cards on the production environment
+var ErrSandboxCard = errors.New("sandbox test cards can't be used in production")
+
+// EnvironmentError is returned when the merchant verification fails, usually
+// because a sandbox key is used in production or the other way round
+type EnvironmentError struct {
+	// Merchant used by the client
+	MerchantID string
+
+	// Environment used by the client
+	Production bool
+
+	// Original verification error
+	Err error
+}
+
+// Returns a descriptive text representation
+func (e *EnvironmentError) Error() string {
+	env := "sandbox"
+	if e.Production {
+		env = "production"
+	}
+	return fmt.Sprintf("merchant %s not available on the %s environment with the provided key: %v",
+		e.MerchantID, env, e.Err)
+}
+
+// Unwrap returns the original verification error
+func (e *EnvironmentError) Unwrap() error {
+	return e.Err
+}
+
+// Merchant verification status, shared by all copies of a client
+type verification struct {
+	mu   sync.Mutex
+	done bool
+}
+
+// Merchant returns the account information for the client merchant
+func (i *Client) Merchant() (*Merchant, error) {
+	b, err := i.request(&requestOptions{
+		operation: "merchant.get",
+		endpoint:  "",
+		method:    http.MethodGet,
+		data:      nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Merchant{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Verify the merchant is available using the client credentials; the check
+// is executed once, unless it fails for reasons unrelated to the environment,
+// for example network errors. Not used in dry-run mode.
+func (i *Client) verify() error {
+	if i.verified == nil || i.dryRun != nil {
+		return nil
+	}
+	i.verified.mu.Lock()
+	defer i.verified.mu.Unlock()
+	if i.verified.done {
+		return nil
+	}
+
+	res, err := i.execute(&requestOptions{
+		operation: "merchant.get",
+		method:    http.MethodGet,
+	}, 1)
+	if err != nil {
+		return err
+	}
+	if res.status == http.StatusUnauthorized || res.status == http.StatusNotFound {
+		return &EnvironmentError{MerchantID: i.merchantID, Production: i.production, Err: res.err}
+	}
+	if res.err != nil {
+		return res.err
+	}
+	m := &Merchant{}
+	json.Unmarshal(res.body, m)
+	if m.ID != "" && m.ID != i.merchantID {
+		return &EnvironmentError{
+			MerchantID: i.merchantID,
+			Production: i.production,
+			Err:        fmt.Errorf("unexpected merchant %s", m.ID),
+		}
+	}
+	i.verified.done = true
+	return nil
+}
+
+// Sandbox test cards are rejected on production clients
+func (i *Client) checkCard(card *Card) error {
+	if !i.production {
+		return nil
+	}
+	if _, ok := LookupTestCard(card.CardNumber); ok {
+		return ErrSandboxCard
+	}
+	return nil
+}
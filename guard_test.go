@@ -0,0 +1,63 @@
+package openpay_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
+)
+
+func TestProductionGuard(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+
+	t.Run("VerifyMerchant", func(t *testing.T) {
+		opts := srv.Options()
+		opts.VerifyMerchant = true
+		opts.UseProduction = true
+		client, _ := openpay.NewClient("sk_live_wrong", srv.MerchantID, opts)
+		_, err := client.Webhooks.List()
+		var envErr *openpay.EnvironmentError
+		if !errors.As(err, &envErr) || !envErr.Production || envErr.MerchantID != srv.MerchantID {
+			t.Fatalf("expected environment error, got %v", err)
+		}
+
+		client, _ = openpay.NewClient(srv.Key, srv.MerchantID, opts)
+		m, err := client.Merchant()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.ID != srv.MerchantID {
+			t.Errorf("invalid merchant: %+v", m)
+		}
+	})
+
+	t.Run("SandboxCards", func(t *testing.T) {
+		card := openpaytest.NewCard()
+		opts := srv.Options()
+		opts.UseProduction = true
+		client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+		if err := client.Charges.AddCard(card); err != openpay.ErrSandboxCard {
+			t.Errorf("expected sandbox card error, got %v", err)
+		}
+		if err := client.Customers.AddCard("c1", card); err != openpay.ErrSandboxCard {
+			t.Errorf("expected sandbox card error, got %v", err)
+		}
+		if err := srv.Client().Charges.AddCard(card); err != nil {
+			t.Errorf("sandbox cards should be accepted on sandbox: %v", err)
+		}
+	})
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		opts := srv.Options()
+		opts.ReadOnly = true
+		client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+		if _, err := client.Webhooks.List(); err != nil {
+			t.Error(err)
+		}
+		if err := client.Webhooks.Delete("wh1"); err != openpay.ErrReadOnly {
+			t.Errorf("expected read-only error, got %v", err)
+		}
+	})
+}
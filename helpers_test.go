@@ -0,0 +1,14 @@
+package openpay
+
+import "time"
+
+// Return a valid sandbox card, never expired
+func testCard() *Card {
+	return &Card{
+		HolderName:      "Rick Sanchez",
+		CardNumber:      "4111111111111111",
+		CVV2:            "401",
+		ExpirationMonth: "10",
+		ExpirationYear:  time.Now().AddDate(5, 0, 0).Format("06"),
+	}
+}
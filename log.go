@@ -0,0 +1,70 @@
+package openpay
+
+import "log/slog"
+
+// Level used to report each possible request outcome
+type LogLevels struct {
+	// Requests completed successfully
+	Success slog.Level
+
+	// Requests rejected by the service with a 4xx status code
+	ClientError slog.Level
+
+	// Requests failed with a 5xx status code
+	ServerError slog.Level
+
+	// Requests failed before getting a response from the service
+	NetworkError slog.Level
+}
+
+// DefaultLogLevels are used when no custom levels are provided
+var DefaultLogLevels = LogLevels{
+	Success:      slog.LevelInfo,
+	ClientError:  slog.LevelWarn,
+	ServerError:  slog.LevelError,
+	NetworkError: slog.LevelError,
+}
+
+// Report a completed request attempt
+func (i *Client) logRequest(r *requestOptions, res *result) {
+	if i.logger == nil {
+		return
+	}
+
+	// The outcome takes into account errors reading the response
+	level := i.logLevels.Success
+	switch res.outcome() {
+	case OutcomeNetworkError:
+		level = i.logLevels.NetworkError
+	case OutcomeServerError:
+		level = i.logLevels.ServerError
+	case OutcomeClientError:
+		level = i.logLevels.ClientError
+	}
+	ctx := i.ctx
+	if !i.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", r.operation),
+		slog.String("method", r.method),
+		slog.String("endpoint", r.endpoint),
+		slog.String("merchant", i.merchantID),
+		slog.Int("status", res.status),
+		slog.Duration("duration", res.latency),
+		slog.Int("attempt", res.attempt),
+	}
+	if res.requestID != "" {
+		attrs = append(attrs, slog.String("request_id", res.requestID))
+	}
+	if res.err != nil {
+		attrs = append(attrs, slog.String("error", res.err.Error()))
+	}
+	if i.logBodies && level <= slog.LevelDebug {
+		attrs = append(attrs,
+			slog.String("request_body", string(RedactJSON(res.requestBody))),
+			slog.String("response_body", string(RedactJSON(res.body))))
+	}
+	i.logger.LogAttrs(ctx, level, "openpay request", attrs...)
+}
@@ -0,0 +1,70 @@
+package openpay_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
+)
+
+func TestLogging(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+
+	buf := &bytes.Buffer{}
+	opts := srv.Options()
+	opts.Logger = slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	opts.LogBodies = true
+	opts.LogLevels = &openpay.LogLevels{
+		Success:      slog.LevelDebug,
+		ClientError:  slog.LevelWarn,
+		ServerError:  slog.LevelError,
+		NetworkError: slog.LevelError,
+	}
+	client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+
+	card := openpaytest.NewCard()
+	if err := client.Charges.AddCard(card); err != nil {
+		t.Fatal(err)
+	}
+	client.Charges.Get("unknown")
+	srv.Inject(openpaytest.Fault{Path: "webhooks", Partial: true})
+	defer srv.ClearFaults()
+	client.Webhooks.List()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0]["level"] != "DEBUG" || entries[0]["endpoint"] != "cards" || entries[0]["merchant"] != srv.MerchantID ||
+		entries[0]["request_body"] == nil {
+		t.Errorf("invalid entry: %v", entries[0])
+	}
+	if entries[1]["level"] != "WARN" || entries[1]["status"] != float64(404) || entries[1]["request_id"] == nil {
+		t.Errorf("invalid entry: %v", entries[1])
+	}
+
+	// Bodies are only included on debug entries
+	if _, ok := entries[1]["response_body"]; ok {
+		t.Errorf("unexpected body: %v", entries[1])
+	}
+
+	// Failed reads are reported as errors even with a successful status code
+	if entries[2]["level"] != "ERROR" || entries[2]["status"] != float64(200) || entries[2]["error"] == nil {
+		t.Errorf("invalid entry: %v", entries[2])
+	}
+	if strings.Contains(buf.String(), "4111111111111111") || strings.Contains(buf.String(), `\"401\"`) {
+		t.Errorf("sensitive data logged: %s", buf)
+	}
+}
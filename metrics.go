@@ -0,0 +1,177 @@
+package openpay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are used for request duration histograms when no
+// custom buckets are provided, values are in seconds
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// MetricsCollector is an observer that aggregates request counts, latency
+// histograms and error codes per operation, and exposes them using the
+// Prometheus text exposition format. It can be mounted directly as the handler
+// for a scrape endpoint.
+type MetricsCollector struct {
+	buckets  []float64
+	mu       sync.Mutex
+	requests map[[2]string]uint64
+	errors   map[[3]string]uint64
+	inFlight map[string]int64
+	latency  map[string]*histogram
+}
+
+// Latency distribution for a single operation
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewMetricsCollector returns a collector using the provided latency buckets,
+// in seconds; if none are provided 'DefaultLatencyBuckets' are used
+func NewMetricsCollector(buckets ...float64) *MetricsCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &MetricsCollector{
+		buckets:  b,
+		requests: make(map[[2]string]uint64),
+		errors:   make(map[[3]string]uint64),
+		inFlight: make(map[string]int64),
+		latency:  make(map[string]*histogram),
+	}
+}
+
+// RequestStarted implements 'Observer'
+func (m *MetricsCollector) RequestStarted(info *RequestInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[info.Operation]++
+}
+
+// RequestFinished implements 'Observer'
+func (m *MetricsCollector) RequestFinished(info *RequestInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[info.Operation]--
+	m.requests[[2]string{info.Operation, string(info.Outcome)}]++
+	if info.Err != nil {
+		code := ""
+		if info.ErrorCode != 0 {
+			code = strconv.FormatUint(uint64(info.ErrorCode), 10)
+		}
+		m.errors[[3]string{info.Operation, info.ErrorCategory, code}]++
+	}
+
+	h, ok := m.latency[info.Operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[info.Operation] = h
+	}
+	secs := info.Latency.Seconds()
+	for i, le := range m.buckets {
+		if secs <= le {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += secs
+}
+
+// WriteTo produces all collected metrics using the Prometheus text exposition
+// format
+func (m *MetricsCollector) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	cw.printf("# HELP openpay_requests_total Requests dispatched to the service.\n")
+	cw.printf("# TYPE openpay_requests_total counter\n")
+	for _, k := range sortedKeys(m.requests) {
+		cw.printf("openpay_requests_total{operation=%s,outcome=%s} %d\n", label(k[0]), label(k[1]), m.requests[k])
+	}
+
+	cw.printf("# HELP openpay_request_errors_total Failed requests by error category and code.\n")
+	cw.printf("# TYPE openpay_request_errors_total counter\n")
+	for _, k := range sortedKeys(m.errors) {
+		cw.printf("openpay_request_errors_total{operation=%s,category=%s,code=%s} %d\n",
+			label(k[0]), label(k[1]), label(k[2]), m.errors[k])
+	}
+
+	cw.printf("# HELP openpay_requests_in_flight Requests currently in progress.\n")
+	cw.printf("# TYPE openpay_requests_in_flight gauge\n")
+	for _, op := range sortedKeys(m.inFlight) {
+		cw.printf("openpay_requests_in_flight{operation=%s} %d\n", label(op), m.inFlight[op])
+	}
+
+	cw.printf("# HELP openpay_request_duration_seconds Time required to complete requests.\n")
+	cw.printf("# TYPE openpay_request_duration_seconds histogram\n")
+	for _, op := range sortedKeys(m.latency) {
+		h, l := m.latency[op], label(op)
+		for i, le := range m.buckets {
+			cw.printf("openpay_request_duration_seconds_bucket{operation=%s,le=\"%s\"} %d\n",
+				l, strconv.FormatFloat(le, 'g', -1, 64), h.counts[i])
+		}
+		cw.printf("openpay_request_duration_seconds_bucket{operation=%s,le=\"+Inf\"} %d\n", l, h.count)
+		cw.printf("openpay_request_duration_seconds_sum{operation=%s} %s\n", l, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		cw.printf("openpay_request_duration_seconds_count{operation=%s} %d\n", l, h.count)
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// ServeHTTP implements 'http.Handler' to expose the metrics to a Prometheus
+// scraper
+func (m *MetricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// Keep track of the bytes written and the first error found
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) printf(format string, args ...interface{}) {
+	if cw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(cw.w, format, args...)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+// Return map keys in a stable order
+func sortedKeys[K [2]string | [3]string | string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+	return keys
+}
+
+// Escape label values as required by the exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Return a quoted label value
+func label(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
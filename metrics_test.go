@@ -0,0 +1,108 @@
+package openpay_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
+)
+
+func TestObserver(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var started, finished []*openpay.RequestInfo
+	opts := srv.Options()
+	opts.Observers = []openpay.Observer{openpay.ObserverFuncs{
+		OnStart: func(info *openpay.RequestInfo) {
+			mu.Lock()
+			started = append(started, info)
+			mu.Unlock()
+		},
+		OnFinish: func(info *openpay.RequestInfo) {
+			mu.Lock()
+			finished = append(finished, info)
+			mu.Unlock()
+		},
+	}}
+	client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+
+	client.Webhooks.List()
+	client.Charges.Get("unknown")
+	if len(started) != 2 || len(finished) != 2 {
+		t.Fatalf("unexpected notifications: %d started, %d finished", len(started), len(finished))
+	}
+	if started[0] != finished[0] {
+		t.Error("start and end callbacks should share the request info")
+	}
+	if finished[0].Operation != "webhooks.list" || finished[0].Outcome != openpay.OutcomeSuccess {
+		t.Errorf("invalid info: %+v", finished[0])
+	}
+	info := finished[1]
+	if info.Operation != "charges.get" || info.Outcome != openpay.OutcomeClientError ||
+		info.ErrorCategory != "request" || info.ErrorCode != 1005 || info.StatusCode != 404 {
+		t.Errorf("invalid info: %+v", info)
+	}
+
+	// Incomplete responses are not successful
+	srv.Inject(openpaytest.Fault{Path: "webhooks", Partial: true})
+	defer srv.ClearFaults()
+	if _, err := client.Webhooks.List(); err == nil {
+		t.Fatal("expected error")
+	}
+	info = finished[2]
+	if info.StatusCode != 200 || info.Outcome != openpay.OutcomeNetworkError || info.ErrorCategory != openpay.ErrCategoryNetwork {
+		t.Errorf("invalid info: %+v", info)
+	}
+}
+
+func TestMetricsCollector(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+
+	metrics := openpay.NewMetricsCollector()
+	opts := srv.Options()
+	opts.Observers = []openpay.Observer{metrics}
+	client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+
+	card := openpaytest.NewCard()
+	if err := client.Charges.AddCard(card); err != nil {
+		t.Fatal(err)
+	}
+	client.Charges.Get("unknown")
+	client.Charges.Get("unknown")
+
+	// Scrape the collector
+	scraper := httptest.NewServer(metrics)
+	defer scraper.Close()
+	res, err := http.Get(scraper.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, _ := ioutil.ReadAll(res.Body)
+	out := string(b)
+
+	for _, line := range []string{
+		`openpay_requests_total{operation="cards.create",outcome="success"} 1`,
+		`openpay_requests_total{operation="charges.get",outcome="client_error"} 2`,
+		`openpay_request_errors_total{operation="charges.get",category="request",code="1005"} 2`,
+		`openpay_requests_in_flight{operation="charges.get"} 0`,
+		`openpay_request_duration_seconds_bucket{operation="charges.get",le="+Inf"} 2`,
+		`openpay_request_duration_seconds_count{operation="cards.create"} 1`,
+		`# TYPE openpay_request_duration_seconds histogram`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing line: %s\n%s", line, out)
+		}
+	}
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "text/plain") {
+		t.Errorf("invalid content type: %s", res.Header.Get("Content-Type"))
+	}
+}
@@ -0,0 +1,176 @@
+package openpay
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// Outcome classifies the result of a request
+type Outcome string
+
+// Possible request outcomes
+const (
+	// Request completed successfully
+	OutcomeSuccess Outcome = "success"
+
+	// Request rejected by the service with a 4xx status code
+	OutcomeClientError Outcome = "client_error"
+
+	// Request failed with a 5xx status code
+	OutcomeServerError Outcome = "server_error"
+
+	// Request failed before getting a response from the service
+	OutcomeNetworkError Outcome = "network_error"
+)
+
+// Error categories reported for failures without a service error
+const (
+	ErrCategoryNetwork = "network"
+	ErrCategoryTimeout = "timeout"
+)
+
+// RequestInfo describes a request dispatched to the service; the same instance
+// is provided to the start and end callbacks, so observers can use it to
+// correlate both events
+type RequestInfo struct {
+	// Logical operation executed, for example 'charges.create' or
+	// 'customers.cards.get'
+	Operation string
+
+	// HTTP method and endpoint, relative to the merchant
+	Method   string
+	Endpoint string
+
+	// Merchant the request was executed for
+	Merchant string
+
+	// Attempt number, starting at 1
+	Attempt int
+
+	// Time the request was dispatched
+	Start time.Time
+
+	// Values below are only available on completion
+
+	// Time required to complete the request
+	Latency time.Duration
+
+	// HTTP status code returned, 0 for network errors
+	StatusCode int
+
+	// Classification of the result
+	Outcome Outcome
+
+	// Service error category ('request', 'internal' or 'gateway'), or
+	// 'network' and 'timeout' for failures without a response
+	ErrorCategory string
+
+	// Service error code, if any
+	ErrorCode uint
+
+	// Unique identifier assigned by the service
+	RequestID string
+
+	// Error returned to the caller, if any
+	Err error
+}
+
+// Observer receives notifications for every request dispatched to the
+// service, for example to collect metrics or tracing spans. Callbacks are
+// executed synchronously and must be safe for concurrent use.
+type Observer interface {
+	// Called right before the request is sent
+	RequestStarted(info *RequestInfo)
+
+	// Called once the request is completed
+	RequestFinished(info *RequestInfo)
+}
+
+// ObserverFuncs allows to use plain functions as an observer; nil callbacks
+// are ignored
+type ObserverFuncs struct {
+	OnStart  func(info *RequestInfo)
+	OnFinish func(info *RequestInfo)
+}
+
+// RequestStarted implements 'Observer'
+func (o ObserverFuncs) RequestStarted(info *RequestInfo) {
+	if o.OnStart != nil {
+		o.OnStart(info)
+	}
+}
+
+// RequestFinished implements 'Observer'
+func (o ObserverFuncs) RequestFinished(info *RequestInfo) {
+	if o.OnFinish != nil {
+		o.OnFinish(info)
+	}
+}
+
+// Notify all registered observers a request is about to be sent
+func (i *Client) observeStart(r *requestOptions, attempt int) *RequestInfo {
+	if len(i.observers) == 0 {
+		return nil
+	}
+	info := &RequestInfo{
+		Operation: r.operation,
+		Method:    r.method,
+		Endpoint:  r.endpoint,
+		Merchant:  i.merchantID,
+		Attempt:   attempt,
+		Start:     time.Now(),
+	}
+	for _, o := range i.observers {
+		o.RequestStarted(info)
+	}
+	return info
+}
+
+// Notify all registered observers a request was completed
+func (i *Client) observeFinish(info *RequestInfo, res *result) {
+	if info == nil {
+		return
+	}
+	info.Latency = res.latency
+	info.StatusCode = res.status
+	info.Outcome = res.outcome()
+	info.RequestID = res.requestID
+	info.Err = res.err
+	info.ErrorCategory, info.ErrorCode = errorCategory(res.err)
+	for _, o := range i.observers {
+		o.RequestFinished(info)
+	}
+}
+
+// Classify the result of a request attempt
+func (res *result) outcome() Outcome {
+	switch {
+	case res.status >= 200 && res.status < 400 && res.err != nil:
+		// Response received but its content couldn't be read
+		return OutcomeNetworkError
+	case res.status == 0:
+		return OutcomeNetworkError
+	case res.status >= 500:
+		return OutcomeServerError
+	case res.status >= 400:
+		return OutcomeClientError
+	}
+	return OutcomeSuccess
+}
+
+// Return the category and code for an error, empty values for nil
+func errorCategory(err error) (string, uint) {
+	if err == nil {
+		return "", 0
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Category, apiErr.Code
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrCategoryTimeout, 0
+	}
+	return ErrCategoryNetwork, 0
+}
@@ -0,0 +1,25 @@
+package openpaytest
+
+import (
+	"time"
+
+	"github.com/fairbank-io/openpay"
+)
+
+// ExpirationYear returns a 2 digits expiration year, 5 years from now, so
+// cards used on tests are never expired
+func ExpirationYear() string {
+	return time.Now().AddDate(5, 0, 0).Format("06")
+}
+
+// NewCard returns a valid sandbox card, approved on charges; any value can be
+// adjusted before using it
+func NewCard() *openpay.Card {
+	return &openpay.Card{
+		HolderName:      "Rick Sanchez",
+		CardNumber:      "4111111111111111",
+		CVV2:            "401",
+		ExpirationMonth: "10",
+		ExpirationYear:  ExpirationYear(),
+	}
+}
@@ -0,0 +1,204 @@
+package openpaytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fairbank-io/openpay"
+)
+
+// Mode of operation for a cassette recorder
+type Mode int
+
+const (
+	// Serve responses from the cassette file, never reaching the network
+	ModeReplay Mode = iota
+
+	// Forward requests to the network and store all interactions
+	ModeRecord
+
+	// Replay if the cassette file exists, record otherwise
+	ModeAuto
+)
+
+// Interaction is a single request and response pair stored in a cassette
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest holds the request values used for matching on replay
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+// RecordedResponse holds the response values returned on replay
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an HTTP transport able to capture client interactions into a
+// cassette file and replay them deterministically. Credentials are never stored
+// and sensitive card data is redacted using 'openpay.RedactJSON'. Use it as the
+// 'Transport' option when creating a client.
+type Recorder struct {
+	// Transport used to reach the network when recording,
+	// http.DefaultTransport if nil
+	Transport http.RoundTripper
+
+	file         string
+	mode         Mode
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder returns a recorder using the provided cassette file. In replay
+// mode the file must exist; when recording, captured interactions are stored
+// in the file by calling Save.
+func NewRecorder(file string, mode Mode) (*Recorder, error) {
+	if mode == ModeAuto {
+		mode = ModeRecord
+		if _, err := os.Stat(file); err == nil {
+			mode = ModeReplay
+		}
+	}
+
+	r := &Recorder{file: file, mode: mode}
+	if mode == ModeReplay {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &r.interactions); err != nil {
+			return nil, fmt.Errorf("cassette %s: %w", file, err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+	return r, nil
+}
+
+// Mode returns the operation mode used by the recorder
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Save stores all recorded interactions in the cassette file; it has no effect
+// when replaying
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.file, b, 0644)
+}
+
+// RoundTrip implements 'http.RoundTripper'
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	rr := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.RawQuery,
+		Body:   string(openpay.RedactJSON(body)),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, rr)
+	}
+
+	t := r.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	res, err := t.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: rr,
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     res.Header.Clone(),
+			Body:       string(openpay.RedactJSON(resBody)),
+		},
+	})
+	r.mu.Unlock()
+	return res, nil
+}
+
+// Return the first unused interaction matching the request
+func (r *Recorder) replay(req *http.Request, rr RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || !matchRequest(in.Request, rr) {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("cassette %s: no recorded interaction matches %s %s?%s with body %s",
+		r.file, rr.Method, rr.Path, rr.Query, rr.Body)
+}
+
+// Requests match on method, path, query and body; JSON bodies are compared by
+// value so formatting differences are ignored
+func matchRequest(a, b RecordedRequest) bool {
+	if a.Method != b.Method || a.Path != b.Path || a.Query != b.Query {
+		return false
+	}
+	if a.Body == b.Body {
+		return true
+	}
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a.Body), &va) != nil || json.Unmarshal([]byte(b.Body), &vb) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
@@ -0,0 +1,68 @@
+package openpaytest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fairbank-io/openpay"
+)
+
+func TestRecorder(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cassette.json")
+	card := NewCard()
+
+	// Record interactions with the fake server
+	srv := NewServer()
+	rec, err := NewRecorder(file, ModeAuto)
+	if err != nil || rec.Mode() != ModeRecord {
+		t.Fatalf("unexpected result: %v", err)
+	}
+	opts := srv.Options()
+	opts.Transport = rec
+	client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+	c1 := *card
+	if err := client.Charges.AddCard(&c1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Charges.Get("unknown"); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	// Secrets must not be stored
+	b, _ := ioutil.ReadFile(file)
+	for _, secret := range []string{srv.Key, card.CardNumber, `"401"`} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("cassette contains sensitive value: %s", secret)
+		}
+	}
+
+	// Replay without network access
+	rec, err = NewRecorder(file, ModeAuto)
+	if err != nil || rec.Mode() != ModeReplay {
+		t.Fatalf("unexpected result: %v", err)
+	}
+	opts.Transport = rec
+	client, _ = openpay.NewClient(srv.Key, srv.MerchantID, opts)
+	c2 := *card
+	if err := client.Charges.AddCard(&c2); err != nil {
+		t.Fatal(err)
+	}
+	if c2.ID != c1.ID || c2.CardNumber != "411111XXXXXX1111" {
+		t.Error("invalid data received")
+	}
+	if _, err := client.Charges.Get("unknown"); err == nil {
+		t.Error("recorded error not replayed")
+	}
+
+	// Unknown and exhausted interactions must fail clearly
+	_, err = client.Charges.Get("unknown")
+	if err == nil || !strings.Contains(err.Error(), "no recorded interaction matches GET") {
+		t.Errorf("unexpected result: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package openpaytest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fault describes an error condition injected by the fake server on matching
+// requests. Conditions are applied in order: latency first, then the first of
+// drop, status, malformed or partial that is set.
+type Fault struct {
+	// HTTP method to match, any method if empty
+	Method string
+
+	// Endpoint to match relative to the merchant, for example 'charges' or
+	// 'charges/*/capture', using 'path.Match' patterns; any endpoint if empty
+	Path string
+
+	// Number of matching requests to let through before the fault is active
+	After int
+
+	// Number of matching requests affected once active, unlimited if 0
+	Times int
+
+	// Delay introduced before responding
+	Latency time.Duration
+
+	// Close the connection without responding
+	Drop bool
+
+	// Respond with an error using the provided status code, for example 502
+	Status int
+
+	// Respond successfully with an invalid JSON body
+	Malformed bool
+
+	// Send only half of the regular response body and close the connection
+	Partial bool
+}
+
+// FaultRule is a fault registered on the server
+type FaultRule struct {
+	Fault
+
+	mu      sync.Mutex
+	seen    int
+	applied int
+}
+
+// Applied returns the number of requests affected by the rule so far
+func (fr *FaultRule) Applied() int {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	return fr.applied
+}
+
+// Determine if the rule should be applied to a request, and track its usage
+func (fr *FaultRule) match(method, endpoint string) bool {
+	if fr.Method != "" && !strings.EqualFold(fr.Method, method) {
+		return false
+	}
+	if fr.Path != "" {
+		if ok, _ := path.Match(strings.Trim(fr.Path, "/"), endpoint); !ok {
+			return false
+		}
+	}
+
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.seen++
+	if fr.seen <= fr.After || (fr.Times > 0 && fr.applied >= fr.Times) {
+		return false
+	}
+	fr.applied++
+	return true
+}
+
+// Inject registers a new fault; for example, to fail the first 2 attempts to
+// create a charge with a gateway error:
+//
+//	srv.Inject(openpaytest.Fault{Method: "POST", Path: "charges", Times: 2, Status: 502})
+func (s *Server) Inject(f Fault) *FaultRule {
+	fr := &FaultRule{Fault: f}
+	s.fmu.Lock()
+	s.faults = append(s.faults, fr)
+	s.fmu.Unlock()
+	return fr
+}
+
+// ClearFaults removes all registered faults
+func (s *Server) ClearFaults() {
+	s.fmu.Lock()
+	s.faults = nil
+	s.fmu.Unlock()
+}
+
+// Return the first registered fault applicable to the request, if any
+func (s *Server) matchFault(r *http.Request) *FaultRule {
+	endpoint := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/"+s.MerchantID), "/")
+	s.fmu.Lock()
+	defer s.fmu.Unlock()
+	for _, fr := range s.faults {
+		if fr.match(r.Method, endpoint) {
+			return fr
+		}
+	}
+	return nil
+}
+
+// Process a request applying any registered fault
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	fr := s.matchFault(r)
+	if fr == nil {
+		s.handle(w, r)
+		return
+	}
+
+	if fr.Latency > 0 {
+		select {
+		case <-time.After(fr.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case fr.Drop:
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	case fr.Status != 0:
+		e := &apiError{fr.Status, 1000, "internal", "Internal server error", nil}
+		if fr.Status != http.StatusInternalServerError {
+			e.code = 1004
+			e.category = "gateway"
+			e.description = "Service unavailable"
+		}
+		s.fail(w, e)
+	case fr.Malformed:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "trx`))
+	case fr.Partial:
+		rec := httptest.NewRecorder()
+		s.handle(rec, r)
+		body := rec.Body.Bytes()
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(rec.Code)
+		w.Write(body[:len(body)/2])
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		panic(http.ErrAbortHandler)
+	default:
+		s.handle(w, r)
+	}
+}
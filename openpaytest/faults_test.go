@@ -0,0 +1,84 @@
+package openpaytest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fairbank-io/openpay"
+)
+
+func TestServerFaults(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	t.Run("FirstAttempts", func(t *testing.T) {
+		rule := srv.Inject(Fault{Method: "GET", Path: "customers", Times: 2, Status: 502})
+		defer srv.ClearFaults()
+		for i := 0; i < 2; i++ {
+			_, err := client.Customers.List(&openpay.CustomersListRequest{})
+			if e, ok := err.(*openpay.APIError); !ok || e.HTTPCode != 502 || e.Category != "gateway" {
+				t.Errorf("unexpected result: %v", err)
+			}
+		}
+		if _, err := client.Customers.List(&openpay.CustomersListRequest{}); err != nil {
+			t.Error(err)
+		}
+		if rule.Applied() != 2 {
+			t.Errorf("expected 2 faults applied, got %d", rule.Applied())
+		}
+	})
+
+	t.Run("After", func(t *testing.T) {
+		srv.Inject(Fault{Path: "webhooks", After: 1, Status: 500})
+		defer srv.ClearFaults()
+		if _, err := client.Webhooks.List(); err != nil {
+			t.Error(err)
+		}
+		_, err := client.Webhooks.List()
+		if e, ok := err.(*openpay.APIError); !ok || e.Category != "internal" {
+			t.Errorf("unexpected result: %v", err)
+		}
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		srv.Inject(Fault{Path: "webhooks", Latency: 100 * time.Millisecond})
+		defer srv.ClearFaults()
+		start := time.Now()
+		if _, err := client.Webhooks.List(); err != nil {
+			t.Error(err)
+		}
+		if time.Since(start) < 100*time.Millisecond {
+			t.Error("latency not applied")
+		}
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		srv.Inject(Fault{Path: "webhooks", Drop: true})
+		defer srv.ClearFaults()
+		if _, err := client.Webhooks.List(); err == nil {
+			t.Error("expected network error")
+		}
+	})
+
+	t.Run("Partial", func(t *testing.T) {
+		srv.Inject(Fault{Path: "customers/*", Partial: true})
+		defer srv.ClearFaults()
+		c := &openpay.Customer{Name: "Rick", Email: "rick@mail.com"}
+		if err := client.Customers.Create(c); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Customers.Get(c.ID); err == nil {
+			t.Error("expected incomplete body error")
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		rule := srv.Inject(Fault{Path: "charges/*", Malformed: true})
+		defer srv.ClearFaults()
+		tx, err := client.Charges.Get("any")
+		if err == nil || tx != nil || rule.Applied() != 1 {
+			t.Errorf("decode error not reported: %v", err)
+		}
+	})
+}
@@ -0,0 +1,383 @@
+package openpaytest
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/fairbank-io/openpay"
+)
+
+// Verify mock types satisfy the API interfaces
+var (
+	_ openpay.ChargesAPI   = (*ChargesMock)(nil)
+	_ openpay.CustomersAPI = (*CustomersMock)(nil)
+	_ openpay.WebhooksAPI  = (*WebhooksMock)(nil)
+)
+
+// TestingT is the subset of testing.TB used by the assertion helpers
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Call records a single invocation of a mock method
+type Call struct {
+	// Method name, for example 'WithCard'
+	Method string
+
+	// Arguments provided, in order
+	Args []interface{}
+}
+
+// Mock keeps track of the calls performed on a mock instance and provides
+// assertion helpers; it is embedded by all mock types
+type Mock struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns all recorded invocations, in order
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the recorded invocations of a specific method, in order
+func (m *Mock) CallsTo(method string) []Call {
+	var list []Call
+	for _, c := range m.Calls() {
+		if c.Method == method {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// Reset discards all recorded invocations
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	m.calls = nil
+	m.mu.Unlock()
+}
+
+// AssertCalled verifies the method was invoked at least once; if arguments are
+// provided, at least one invocation must have used equivalent values
+func (m *Mock) AssertCalled(t TestingT, method string, args ...interface{}) bool {
+	t.Helper()
+	calls := m.CallsTo(method)
+	if len(calls) == 0 {
+		t.Errorf("expected call to %s, got none", method)
+		return false
+	}
+	if len(args) == 0 {
+		return true
+	}
+	for _, c := range calls {
+		if reflect.DeepEqual(c.Args, args) {
+			return true
+		}
+	}
+	t.Errorf("expected call to %s with arguments %+v, got %+v", method, args, calls)
+	return false
+}
+
+// AssertNotCalled verifies the method was never invoked
+func (m *Mock) AssertNotCalled(t TestingT, method string) bool {
+	t.Helper()
+	if n := len(m.CallsTo(method)); n > 0 {
+		t.Errorf("expected no calls to %s, got %d", method, n)
+		return false
+	}
+	return true
+}
+
+// AssertNumberOfCalls verifies the method was invoked exactly 'n' times
+func (m *Mock) AssertNumberOfCalls(t TestingT, method string, n int) bool {
+	t.Helper()
+	if got := len(m.CallsTo(method)); got != n {
+		t.Errorf("expected %d calls to %s, got %d", n, method, got)
+		return false
+	}
+	return true
+}
+
+// Register a new invocation
+func (m *Mock) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+	m.mu.Unlock()
+}
+
+// Mocks groups mock implementations for all API interfaces
+type Mocks struct {
+	Charges   *ChargesMock
+	Customers *CustomersMock
+	Webhooks  *WebhooksMock
+}
+
+// NewMocks returns a new set of mock implementations with no programmed
+// responses
+func NewMocks() *Mocks {
+	return &Mocks{
+		Charges:   &ChargesMock{},
+		Customers: &CustomersMock{},
+		Webhooks:  &WebhooksMock{},
+	}
+}
+
+// Client returns a client instance backed by the mock implementations; other
+// operations, like 'Merchant', fail with 'openpay.ErrNotConfigured'
+func (m *Mocks) Client() *openpay.Client {
+	return &openpay.Client{
+		Charges:   m.Charges,
+		Customers: m.Customers,
+		Webhooks:  m.Webhooks,
+	}
+}
+
+// ChargesMock is a programmable implementation of 'openpay.ChargesAPI'. Methods
+// without a programmed function succeed returning empty values.
+type ChargesMock struct {
+	Mock
+
+	AddCardFunc  func(card *openpay.Card) error
+	GetFunc      func(txID string) (*openpay.Transaction, error)
+	ListFunc     func(req *openpay.ChargesListRequest) ([]openpay.Transaction, error)
+	AtStoreFunc  func(charge *openpay.ChargeAtStore) (*openpay.Transaction, error)
+	AtBankFunc   func(charge *openpay.ChargeAtBank) (*openpay.Transaction, error)
+	WithCardFunc func(charge *openpay.ChargeWithStoredCard) (*openpay.Transaction, error)
+	CaptureFunc  func(txID string, amount float32) (*openpay.Transaction, error)
+	RefundFunc   func(txID string, amount float32, description string) (*openpay.Transaction, error)
+}
+
+func (m *ChargesMock) AddCard(card *openpay.Card) error {
+	m.record("AddCard", card)
+	if m.AddCardFunc != nil {
+		return m.AddCardFunc(card)
+	}
+	return nil
+}
+
+func (m *ChargesMock) Get(txID string) (*openpay.Transaction, error) {
+	m.record("Get", txID)
+	if m.GetFunc != nil {
+		return m.GetFunc(txID)
+	}
+	return &openpay.Transaction{}, nil
+}
+
+func (m *ChargesMock) List(req *openpay.ChargesListRequest) ([]openpay.Transaction, error) {
+	m.record("List", req)
+	if m.ListFunc != nil {
+		return m.ListFunc(req)
+	}
+	return nil, nil
+}
+
+func (m *ChargesMock) AtStore(charge *openpay.ChargeAtStore) (*openpay.Transaction, error) {
+	m.record("AtStore", charge)
+	if m.AtStoreFunc != nil {
+		return m.AtStoreFunc(charge)
+	}
+	return &openpay.Transaction{}, nil
+}
+
+func (m *ChargesMock) AtBank(charge *openpay.ChargeAtBank) (*openpay.Transaction, error) {
+	m.record("AtBank", charge)
+	if m.AtBankFunc != nil {
+		return m.AtBankFunc(charge)
+	}
+	return &openpay.Transaction{}, nil
+}
+
+func (m *ChargesMock) WithCard(charge *openpay.ChargeWithStoredCard) (*openpay.Transaction, error) {
+	m.record("WithCard", charge)
+	if m.WithCardFunc != nil {
+		return m.WithCardFunc(charge)
+	}
+	return &openpay.Transaction{}, nil
+}
+
+func (m *ChargesMock) Capture(txID string, amount float32) (*openpay.Transaction, error) {
+	m.record("Capture", txID, amount)
+	if m.CaptureFunc != nil {
+		return m.CaptureFunc(txID, amount)
+	}
+	return &openpay.Transaction{}, nil
+}
+
+func (m *ChargesMock) Refund(txID string, amount float32, description string) (*openpay.Transaction, error) {
+	m.record("Refund", txID, amount, description)
+	if m.RefundFunc != nil {
+		return m.RefundFunc(txID, amount, description)
+	}
+	return &openpay.Transaction{}, nil
+}
+
+// CustomersMock is a programmable implementation of 'openpay.CustomersAPI'.
+// Methods without a programmed function succeed returning empty values.
+type CustomersMock struct {
+	Mock
+
+	CreateFunc            func(customer *openpay.Customer) error
+	UpdateFunc            func(customer *openpay.Customer) error
+	GetFunc               func(customerID string) (*openpay.Customer, error)
+	ListFunc              func(req *openpay.CustomersListRequest) ([]openpay.Customer, error)
+	DeleteFunc            func(customerID string) error
+	AddCardFunc           func(customerID string, card *openpay.Card) error
+	GetCardFunc           func(customerID, cardID string) (*openpay.Card, error)
+	ListCardsFunc         func(customerID string, req *openpay.ListRequest) ([]openpay.Card, error)
+	DeleteCardFunc        func(customerID, cardID string) error
+	AddBankAccountFunc    func(customerID string, acc *openpay.BankAccount) error
+	GetBankAccountFunc    func(customerID, accountID string) (*openpay.BankAccount, error)
+	ListBankAccountsFunc  func(customerID string, req *openpay.ListRequest) ([]openpay.BankAccount, error)
+	DeleteBankAccountFunc func(customerID, accountID string) error
+}
+
+func (m *CustomersMock) Create(customer *openpay.Customer) error {
+	m.record("Create", customer)
+	if m.CreateFunc != nil {
+		return m.CreateFunc(customer)
+	}
+	return nil
+}
+
+func (m *CustomersMock) Update(customer *openpay.Customer) error {
+	m.record("Update", customer)
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(customer)
+	}
+	return nil
+}
+
+func (m *CustomersMock) Get(customerID string) (*openpay.Customer, error) {
+	m.record("Get", customerID)
+	if m.GetFunc != nil {
+		return m.GetFunc(customerID)
+	}
+	return &openpay.Customer{}, nil
+}
+
+func (m *CustomersMock) List(req *openpay.CustomersListRequest) ([]openpay.Customer, error) {
+	m.record("List", req)
+	if m.ListFunc != nil {
+		return m.ListFunc(req)
+	}
+	return nil, nil
+}
+
+func (m *CustomersMock) Delete(customerID string) error {
+	m.record("Delete", customerID)
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(customerID)
+	}
+	return nil
+}
+
+func (m *CustomersMock) AddCard(customerID string, card *openpay.Card) error {
+	m.record("AddCard", customerID, card)
+	if m.AddCardFunc != nil {
+		return m.AddCardFunc(customerID, card)
+	}
+	return nil
+}
+
+func (m *CustomersMock) GetCard(customerID, cardID string) (*openpay.Card, error) {
+	m.record("GetCard", customerID, cardID)
+	if m.GetCardFunc != nil {
+		return m.GetCardFunc(customerID, cardID)
+	}
+	return &openpay.Card{}, nil
+}
+
+func (m *CustomersMock) ListCards(customerID string, req *openpay.ListRequest) ([]openpay.Card, error) {
+	m.record("ListCards", customerID, req)
+	if m.ListCardsFunc != nil {
+		return m.ListCardsFunc(customerID, req)
+	}
+	return nil, nil
+}
+
+func (m *CustomersMock) DeleteCard(customerID, cardID string) error {
+	m.record("DeleteCard", customerID, cardID)
+	if m.DeleteCardFunc != nil {
+		return m.DeleteCardFunc(customerID, cardID)
+	}
+	return nil
+}
+
+func (m *CustomersMock) AddBankAccount(customerID string, acc *openpay.BankAccount) error {
+	m.record("AddBankAccount", customerID, acc)
+	if m.AddBankAccountFunc != nil {
+		return m.AddBankAccountFunc(customerID, acc)
+	}
+	return nil
+}
+
+func (m *CustomersMock) GetBankAccount(customerID, accountID string) (*openpay.BankAccount, error) {
+	m.record("GetBankAccount", customerID, accountID)
+	if m.GetBankAccountFunc != nil {
+		return m.GetBankAccountFunc(customerID, accountID)
+	}
+	return &openpay.BankAccount{}, nil
+}
+
+func (m *CustomersMock) ListBankAccounts(customerID string, req *openpay.ListRequest) ([]openpay.BankAccount, error) {
+	m.record("ListBankAccounts", customerID, req)
+	if m.ListBankAccountsFunc != nil {
+		return m.ListBankAccountsFunc(customerID, req)
+	}
+	return nil, nil
+}
+
+func (m *CustomersMock) DeleteBankAccount(customerID, accountID string) error {
+	m.record("DeleteBankAccount", customerID, accountID)
+	if m.DeleteBankAccountFunc != nil {
+		return m.DeleteBankAccountFunc(customerID, accountID)
+	}
+	return nil
+}
+
+// WebhooksMock is a programmable implementation of 'openpay.WebhooksAPI'.
+// Methods without a programmed function succeed returning empty values.
+type WebhooksMock struct {
+	Mock
+
+	CreateFunc func(wh *openpay.Webhook) error
+	GetFunc    func(whID string) (*openpay.Webhook, error)
+	ListFunc   func() ([]openpay.Webhook, error)
+	DeleteFunc func(whID string) error
+}
+
+func (m *WebhooksMock) Create(wh *openpay.Webhook) error {
+	m.record("Create", wh)
+	if m.CreateFunc != nil {
+		return m.CreateFunc(wh)
+	}
+	return nil
+}
+
+func (m *WebhooksMock) Get(whID string) (*openpay.Webhook, error) {
+	m.record("Get", whID)
+	if m.GetFunc != nil {
+		return m.GetFunc(whID)
+	}
+	return &openpay.Webhook{}, nil
+}
+
+func (m *WebhooksMock) List() ([]openpay.Webhook, error) {
+	m.record("List")
+	if m.ListFunc != nil {
+		return m.ListFunc()
+	}
+	return nil, nil
+}
+
+func (m *WebhooksMock) Delete(whID string) error {
+	m.record("Delete", whID)
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(whID)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package openpaytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fairbank-io/openpay"
+)
+
+// Captures assertion failures instead of reporting them
+type recorderT struct {
+	errors []string
+}
+
+func (r *recorderT) Helper() {}
+
+func (r *recorderT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMocks(t *testing.T) {
+	mocks := NewMocks()
+	mocks.Charges.CaptureFunc = func(txID string, amount float32) (*openpay.Transaction, error) {
+		if txID == "declined" {
+			return nil, &openpay.APIError{Code: 3001}
+		}
+		return &openpay.Transaction{ID: txID, Amount: amount, Status: "completed"}, nil
+	}
+	client := mocks.Client()
+
+	tx, err := client.Charges.Capture("tx1", 100)
+	if err != nil || tx.Status != "completed" {
+		t.Errorf("unexpected result: %v", err)
+	}
+	if _, err := client.Charges.Capture("declined", 100); err == nil {
+		t.Error("programmed error not returned")
+	}
+	if _, err := client.Charges.Get("tx1"); err != nil {
+		t.Error(err)
+	}
+
+	mocks.Charges.AssertCalled(t, "Capture", "tx1", float32(100))
+	mocks.Charges.AssertNumberOfCalls(t, "Capture", 2)
+	mocks.Charges.AssertNotCalled(t, "Refund")
+	mocks.Webhooks.AssertNotCalled(t, "Create")
+
+	// Failed assertions must be reported
+	rt := &recorderT{}
+	mocks.Charges.AssertCalled(rt, "Capture", "tx2", float32(100))
+	mocks.Charges.AssertNotCalled(rt, "Get")
+	mocks.Customers.AssertCalled(rt, "Create")
+	if len(rt.errors) != 3 {
+		t.Errorf("expected 3 failed assertions, got %v", rt.errors)
+	}
+
+	mocks.Charges.Reset()
+	mocks.Charges.AssertNumberOfCalls(t, "Capture", 0)
+}
+
+func TestMocksWithContext(t *testing.T) {
+	mocks := NewMocks()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := mocks.Client().WithContext(ctx)
+	if client.Charges != openpay.ChargesAPI(mocks.Charges) || client.Customers != openpay.CustomersAPI(mocks.Customers) ||
+		client.Webhooks != openpay.WebhooksAPI(mocks.Webhooks) {
+		t.Fatal("mocks should be preserved")
+	}
+	if _, err := client.Charges.Get("x"); err != nil {
+		t.Error(err)
+	}
+	mocks.Charges.AssertCalled(t, "Get", "x")
+
+	// Operations without a mock implementation fail cleanly
+	if _, err := client.Merchant(); !errors.Is(err, openpay.ErrNotConfigured) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMocksWithResponse(t *testing.T) {
+	mocks := NewMocks()
+	meta := openpay.Response{}
+	client := mocks.Client().WithResponse(&meta)
+	if client.Webhooks != openpay.WebhooksAPI(mocks.Webhooks) {
+		t.Fatal("mocks should be preserved")
+	}
+	if _, err := client.Webhooks.List(); err != nil {
+		t.Error(err)
+	}
+	mocks.Webhooks.AssertNumberOfCalls(t, "List", 1)
+}
@@ -0,0 +1,646 @@
+package openpaytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fairbank-io/openpay"
+)
+
+// Credentials accepted by default by the fake server
+const (
+	DefaultKey        = "sk_test_openpaytest"
+	DefaultMerchantID = "mtestopenpaytest"
+)
+
+// Server is an in-memory implementation of the service API, suitable to run
+// client tests without network access. Customers, cards, bank accounts, charges
+// and webhooks are supported. Card charges using any of the numbers listed in
+// 'openpay.SandboxCards' produce the same results as the sandbox environment.
+type Server struct {
+	// Underlying HTTP test server
+	*httptest.Server
+
+	// API key accepted by the server
+	Key string
+
+	// Merchant identifier accepted by the server
+	MerchantID string
+
+	mu           sync.Mutex
+	counter      int
+	requests     int
+	customers    map[string]*openpay.Customer
+	cards        map[string]*openpay.Card
+	bankAccounts map[string]*openpay.BankAccount
+	charges      map[string]*openpay.Transaction
+	webhooks     map[string]*openpay.Webhook
+	owners       map[string]string
+	numbers      map[string]string
+	order        []string
+
+	fmu    sync.Mutex
+	faults []*FaultRule
+}
+
+// Service error reported by the fake server
+type apiError struct {
+	status      int
+	code        uint
+	category    string
+	description string
+	fraudRules  []string
+}
+
+var (
+	errUnauthorized = &apiError{http.StatusUnauthorized, 1002, "request", "The api key or merchant id are invalid", nil}
+	errNotFound     = &apiError{http.StatusNotFound, 1005, "request", "The requested resource doesn't exist", nil}
+	errConflict     = &apiError{http.StatusUnprocessableEntity, 1003, "request", "The operation is not allowed for the resource current state", nil}
+	errDuplicated   = &apiError{http.StatusConflict, 1006, "request", "The order_id has already been processed", nil}
+)
+
+// Returns a request error with a custom description
+func errBadRequest(description string) *apiError {
+	return &apiError{http.StatusBadRequest, 1001, "request", description, nil}
+}
+
+// NewServer starts a new fake server using the default credentials. Callers
+// should call Close when finished to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Key:          DefaultKey,
+		MerchantID:   DefaultMerchantID,
+		customers:    make(map[string]*openpay.Customer),
+		cards:        make(map[string]*openpay.Card),
+		bankAccounts: make(map[string]*openpay.BankAccount),
+		charges:      make(map[string]*openpay.Transaction),
+		webhooks:     make(map[string]*openpay.Webhook),
+		owners:       make(map[string]string),
+		numbers:      make(map[string]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Options returns client configuration values pointing to the fake server
+func (s *Server) Options() *openpay.Options {
+	return &openpay.Options{
+		Timeout:        5,
+		KeepAlive:      30,
+		MaxConnections: 10,
+		APIVersion:     "v1",
+		Endpoint:       s.URL,
+	}
+}
+
+// Client returns a new client instance connected to the fake server
+func (s *Server) Client() *openpay.Client {
+	c, err := openpay.NewClient(s.Key, s.MerchantID, s.Options())
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// SetKey replaces the API key accepted by the server, useful to simulate
+// credentials rotation
+func (s *Server) SetKey(key string) {
+	s.mu.Lock()
+	s.Key = key
+	s.mu.Unlock()
+}
+
+// SetChargeStatus updates the status of an existing charge, useful to simulate
+// asynchronous state changes like payments at stores or banks
+func (s *Server) SetChargeStatus(txID string, status openpay.TransactionStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, ok := s.charges[txID]
+	if !ok {
+		return fmt.Errorf("unknown charge: %s", txID)
+	}
+	tx.Status = status
+	return nil
+}
+
+// Main request handler
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.assignRequestID(w)
+	body, _ := ioutil.ReadAll(r.Body)
+	out, err := s.dispatch(r, body)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if out == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method == http.MethodPost {
+		w.WriteHeader(http.StatusCreated)
+	}
+	w.Write(out)
+}
+
+// Process a request against the server state; the result is encoded before
+// releasing the lock since it may reference stored objects
+func (s *Server) dispatch(r *http.Request, body []byte) ([]byte, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Validate credentials and merchant
+	prefix := "/v1/" + s.MerchantID
+	key, _, _ := r.BasicAuth()
+	if key != s.Key || !strings.HasPrefix(r.URL.Path, prefix) {
+		return nil, errUnauthorized
+	}
+
+	seg := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/"), "/")
+	var res interface{}
+	var err *apiError
+	switch seg[0] {
+	case "":
+		res, err = s.routeMerchant(r.Method)
+	case "customers":
+		res, err = s.routeCustomers(r.Method, seg[1:], body)
+	case "cards":
+		res, err = s.routeCards(r.Method, "", seg[1:], body)
+	case "charges":
+		res, err = s.routeCharges(r.Method, seg[1:], body)
+	case "webhooks":
+		res, err = s.routeWebhooks(r.Method, seg[1:], body)
+	default:
+		err = errNotFound
+	}
+	if err != nil || res == nil {
+		return nil, err
+	}
+	out, _ := json.Marshal(res)
+	return append(out, '\n'), nil
+}
+
+// Every response includes a unique request identifier
+func (s *Server) assignRequestID(w http.ResponseWriter) {
+	s.mu.Lock()
+	s.requests++
+	id := s.requests
+	s.mu.Unlock()
+	w.Header().Set("X-Request-Id", fmt.Sprintf("req-%08d", id))
+}
+
+// Report an error using the service format
+func (s *Server) fail(w http.ResponseWriter, e *apiError) {
+	if w.Header().Get("X-Request-Id") == "" {
+		s.assignRequestID(w)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.status)
+	json.NewEncoder(w).Encode(&openpay.APIError{
+		Category:    e.category,
+		Code:        e.code,
+		HTTPCode:    uint(e.status),
+		Description: e.description,
+		RequestID:   w.Header().Get("X-Request-Id"),
+		FraudRules:  e.fraudRules,
+	})
+}
+
+func (s *Server) routeMerchant(method string) (interface{}, *apiError) {
+	if method != http.MethodGet {
+		return nil, errNotFound
+	}
+	return &openpay.Merchant{
+		ID:     s.MerchantID,
+		Name:   "openpaytest",
+		Email:  "merchant@openpaytest.local",
+		Status: "active",
+	}, nil
+}
+
+func (s *Server) routeCustomers(method string, seg []string, body []byte) (interface{}, *apiError) {
+	if len(seg) == 0 {
+		switch method {
+		case http.MethodPost:
+			return s.createCustomer(body)
+		case http.MethodGet:
+			req := &openpay.CustomersListRequest{}
+			json.Unmarshal(body, req)
+			var list []openpay.Customer
+			for _, id := range s.order {
+				if c, ok := s.customers[id]; ok && (req.ExternalID == "" || req.ExternalID == c.ExternalID) {
+					list = append(list, *c)
+				}
+			}
+			return paginate(list, &req.ListRequest), nil
+		}
+		return nil, errNotFound
+	}
+
+	c, ok := s.customers[seg[0]]
+	if !ok {
+		return nil, errNotFound
+	}
+	if len(seg) > 1 {
+		switch seg[1] {
+		case "cards":
+			return s.routeCards(method, c.ID, seg[2:], body)
+		case "bankaccounts":
+			return s.routeBankAccounts(method, c.ID, seg[2:], body)
+		}
+		return nil, errNotFound
+	}
+
+	switch method {
+	case http.MethodGet:
+		return c, nil
+	case http.MethodPut:
+		update := &openpay.Customer{}
+		if err := json.Unmarshal(body, update); err != nil {
+			return nil, errBadRequest("invalid request body")
+		}
+		update.ID = c.ID
+		update.CreationDate = c.CreationDate
+		update.Status = c.Status
+		update.Balance = c.Balance
+		update.Clabe = c.Clabe
+		update.Store = c.Store
+		s.customers[c.ID] = update
+		return update, nil
+	case http.MethodDelete:
+		delete(s.customers, c.ID)
+		return nil, nil
+	}
+	return nil, errNotFound
+}
+
+func (s *Server) createCustomer(body []byte) (interface{}, *apiError) {
+	c := &openpay.Customer{}
+	if err := json.Unmarshal(body, c); err != nil {
+		return nil, errBadRequest("invalid request body")
+	}
+	if c.Name == "" {
+		return nil, errBadRequest("name is required")
+	}
+	if c.Email == "" {
+		return nil, errBadRequest("email is required")
+	}
+	c.ID = s.newID()
+	c.CreationDate = now()
+	c.Status = openpay.CustomerActive
+	c.Store = openpay.Store{Reference: strings.ToUpper(c.ID[:15])}
+	s.customers[c.ID] = c
+	return c, nil
+}
+
+func (s *Server) routeCards(method, customerID string, seg []string, body []byte) (interface{}, *apiError) {
+	if len(seg) == 0 {
+		switch method {
+		case http.MethodPost:
+			return s.createCard(customerID, body)
+		case http.MethodGet:
+			req := &openpay.ListRequest{}
+			json.Unmarshal(body, req)
+			var list []openpay.Card
+			for _, id := range s.order {
+				if c, ok := s.cards[id]; ok && c.CustomerID == customerID {
+					list = append(list, *c)
+				}
+			}
+			return paginate(list, req), nil
+		}
+		return nil, errNotFound
+	}
+
+	c, ok := s.cards[seg[0]]
+	if !ok || c.CustomerID != customerID || len(seg) > 1 {
+		return nil, errNotFound
+	}
+	switch method {
+	case http.MethodGet:
+		return c, nil
+	case http.MethodDelete:
+		delete(s.cards, c.ID)
+		return nil, nil
+	}
+	return nil, errNotFound
+}
+
+func (s *Server) createCard(customerID string, body []byte) (interface{}, *apiError) {
+	c := &openpay.Card{}
+	if err := json.Unmarshal(body, c); err != nil {
+		return nil, errBadRequest("invalid request body")
+	}
+	if len(c.CardNumber) < 13 || len(c.CardNumber) > 19 {
+		return nil, errBadRequest("card_number length is invalid")
+	}
+	if c.HolderName == "" || c.ExpirationMonth == "" || c.ExpirationYear == "" {
+		return nil, errBadRequest("holder_name, expiration_month and expiration_year are required")
+	}
+	c.ID = s.newID()
+	c.CreationDate = now()
+	c.CustomerID = customerID
+	if bin, ok := openpay.LookupBIN(c.CardNumber); ok {
+		c.Brand = bin.Brand
+	}
+	c.Type = openpay.CardDebit
+	c.BankName = "Banamex"
+	c.BankCode = "002"
+	c.AllowsCharges = true
+	c.AllowsPayouts = true
+	s.numbers[c.ID] = c.CardNumber
+	c.CardNumber = maskDigits(c.CardNumber, 6, 4)
+	c.CVV2 = ""
+	s.cards[c.ID] = c
+	return c, nil
+}
+
+func (s *Server) routeBankAccounts(method, customerID string, seg []string, body []byte) (interface{}, *apiError) {
+	if len(seg) == 0 {
+		switch method {
+		case http.MethodPost:
+			acc := &openpay.BankAccount{}
+			if err := json.Unmarshal(body, acc); err != nil {
+				return nil, errBadRequest("invalid request body")
+			}
+			if len(acc.Clabe) != 18 {
+				return nil, errBadRequest("clabe must be 18 digits long")
+			}
+			if acc.HolderName == "" {
+				return nil, errBadRequest("holder_name is required")
+			}
+			acc.ID = s.newID()
+			acc.CreationDate = now()
+			acc.BankCode = acc.Clabe[:3]
+			acc.BankName, _ = openpay.LookupBank(acc.BankCode)
+			acc.Clabe = maskDigits(acc.Clabe, 3, 5)
+			s.bankAccounts[acc.ID] = acc
+			s.owners[acc.ID] = customerID
+			return acc, nil
+		case http.MethodGet:
+			req := &openpay.ListRequest{}
+			json.Unmarshal(body, req)
+			var list []openpay.BankAccount
+			for _, id := range s.order {
+				if acc, ok := s.bankAccounts[id]; ok && s.owners[id] == customerID {
+					list = append(list, *acc)
+				}
+			}
+			return paginate(list, req), nil
+		}
+		return nil, errNotFound
+	}
+
+	acc, ok := s.bankAccounts[seg[0]]
+	if !ok || s.owners[acc.ID] != customerID || len(seg) > 1 {
+		return nil, errNotFound
+	}
+	switch method {
+	case http.MethodGet:
+		return acc, nil
+	case http.MethodDelete:
+		delete(s.bankAccounts, acc.ID)
+		return nil, nil
+	}
+	return nil, errNotFound
+}
+
+// Charge request fields, covering all supported charge methods
+type chargeRequest struct {
+	openpay.Charge
+	SourceID string    `json:"source_id"`
+	Capture  *bool     `json:"capture"`
+	DueDate  time.Time `json:"due_date"`
+}
+
+func (s *Server) routeCharges(method string, seg []string, body []byte) (interface{}, *apiError) {
+	if len(seg) == 0 {
+		switch method {
+		case http.MethodPost:
+			return s.createCharge(body)
+		case http.MethodGet:
+			req := &openpay.ChargesListRequest{}
+			json.Unmarshal(body, req)
+			var list []openpay.Transaction
+			for _, id := range s.order {
+				tx, ok := s.charges[id]
+				if !ok {
+					continue
+				}
+				if req.OrderID != "" && req.OrderID != tx.OrderID {
+					continue
+				}
+				if req.Status != "" && req.Status.Normalize() != tx.Status.Normalize() {
+					continue
+				}
+				list = append(list, *tx)
+			}
+			return list, nil
+		}
+		return nil, errNotFound
+	}
+
+	tx, ok := s.charges[seg[0]]
+	if !ok || len(seg) > 2 {
+		return nil, errNotFound
+	}
+	if len(seg) == 1 {
+		if method != http.MethodGet {
+			return nil, errNotFound
+		}
+		return tx, nil
+	}
+	if method != http.MethodPost {
+		return nil, errNotFound
+	}
+
+	req := &struct {
+		Amount float32 `json:"amount"`
+	}{}
+	json.Unmarshal(body, req)
+	switch seg[1] {
+	case "capture":
+		// Only pre-authorized card charges can be captured
+		if tx.Method != openpay.MethodCard || !tx.Status.Capturable() {
+			return nil, errConflict
+		}
+		if req.Amount > tx.Amount {
+			return nil, errBadRequest("amount exceeds the authorized value")
+		}
+		if req.Amount > 0 {
+			tx.Amount = req.Amount
+		}
+		tx.Status = openpay.StatusCompleted
+		return tx, nil
+	case "refund":
+		// Only completed card charges can be refunded
+		if tx.Method != openpay.MethodCard || !tx.Status.Refundable() {
+			return nil, errConflict
+		}
+		if req.Amount > tx.Amount {
+			return nil, errBadRequest("amount exceeds the charged value")
+		}
+		// Partial refunds keep the charge completed
+		if req.Amount == 0 || req.Amount == tx.Amount {
+			tx.Status = openpay.StatusRefunded
+		}
+		return tx, nil
+	}
+	return nil, errNotFound
+}
+
+func (s *Server) createCharge(body []byte) (interface{}, *apiError) {
+	req := &chargeRequest{}
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, errBadRequest("invalid request body")
+	}
+	if req.Amount <= 0 {
+		return nil, errBadRequest("amount must be greater than zero")
+	}
+	if req.OrderID != "" {
+		for _, tx := range s.charges {
+			if tx.OrderID == req.OrderID {
+				return nil, errDuplicated
+			}
+		}
+	}
+
+	tx := &openpay.Transaction{
+		ID:              s.newID(),
+		TransactionType: openpay.TransactionCharge,
+		OperationType:   openpay.OperationIn,
+		OrderID:         req.OrderID,
+		CustomerID:      req.Customer.ID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		Method:          req.Method,
+		CreationDate:    now(),
+		Description:     req.Description,
+	}
+	if tx.Currency == "" {
+		tx.Currency = openpay.CurrencyMXN
+	}
+
+	switch req.Method {
+	case openpay.MethodCard:
+		card, ok := s.cards[req.SourceID]
+		if !ok {
+			return nil, errNotFound
+		}
+		c := *card
+		tx.Card = &c
+
+		// Sandbox test cards produce predefined results; failed charges are
+		// kept for reference, as the service does
+		if tc, ok := openpay.LookupTestCard(s.numbers[card.ID]); ok && tc.ErrorCode != 0 {
+			tx.Status = openpay.StatusFailed
+			tx.ErrorMessage = tc.Description
+			s.charges[tx.ID] = tx
+			e := tc.ChargeError()
+			return nil, &apiError{int(e.HTTPCode), e.Code, e.Category, e.Description, e.FraudRules}
+		}
+		tx.Authorization = fmt.Sprintf("%06d", s.counter%1000000)
+		tx.Status = openpay.StatusCompleted
+		if req.Capture != nil && !*req.Capture {
+			tx.Status = openpay.StatusInProgress
+		}
+	case openpay.MethodStore, openpay.MethodBankAccount:
+		if !req.DueDate.IsZero() && req.DueDate.Before(time.Now()) {
+			return nil, errBadRequest("due_date must be in the future")
+		}
+		tx.DueDate = req.DueDate
+		tx.Status = openpay.StatusInProgress
+	default:
+		return nil, errBadRequest("invalid charge method")
+	}
+	s.charges[tx.ID] = tx
+	return tx, nil
+}
+
+func (s *Server) routeWebhooks(method string, seg []string, body []byte) (interface{}, *apiError) {
+	if len(seg) == 0 {
+		switch method {
+		case http.MethodPost:
+			wh := &openpay.Webhook{}
+			if err := json.Unmarshal(body, wh); err != nil {
+				return nil, errBadRequest("invalid request body")
+			}
+			if !strings.HasPrefix(wh.URL, "http") {
+				return nil, errBadRequest("url is invalid")
+			}
+			if len(wh.EventTypes) == 0 {
+				wh.EventTypes = openpay.AllEvents()
+			}
+			wh.ID = s.newID()
+			wh.Status = "verified"
+			wh.Password = ""
+			s.webhooks[wh.ID] = wh
+			return wh, nil
+		case http.MethodGet:
+			list := []openpay.Webhook{}
+			for _, id := range s.order {
+				if wh, ok := s.webhooks[id]; ok {
+					list = append(list, *wh)
+				}
+			}
+			return list, nil
+		}
+		return nil, errNotFound
+	}
+
+	wh, ok := s.webhooks[seg[0]]
+	if !ok || len(seg) > 1 {
+		return nil, errNotFound
+	}
+	switch method {
+	case http.MethodGet:
+		return wh, nil
+	case http.MethodDelete:
+		delete(s.webhooks, wh.ID)
+		return nil, nil
+	}
+	return nil, errNotFound
+}
+
+// Generate a new resource identifier, keeping track of creation order
+func (s *Server) newID() string {
+	s.counter++
+	id := randomID()
+	s.order = append(s.order, id)
+	return id
+}
+
+// Apply the pagination settings to a list of records
+func paginate[T any](list []T, req *openpay.ListRequest) []T {
+	if list == nil {
+		list = []T{}
+	}
+	if req.Offset >= uint(len(list)) {
+		return list[:0]
+	}
+	list = list[req.Offset:]
+	if req.Limit > 0 && req.Limit < uint(len(list)) {
+		list = list[:req.Limit]
+	}
+	return list
+}
+
+// Hide all but the first and last digits of a value
+func maskDigits(v string, first, last int) string {
+	if len(v) <= first+last {
+		return v
+	}
+	return v[:first] + strings.Repeat("X", len(v)-first-last) + v[len(v)-last:]
+}
+
+// Current time as reported by the service
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
@@ -0,0 +1,202 @@
+package openpaytest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fairbank-io/openpay"
+)
+
+func TestServer(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	customer := &openpay.Customer{
+		Name:     "Rick",
+		LastName: "Sanchez",
+		Email:    "rick@mail.com",
+	}
+	if err := client.Customers.Create(customer); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Credentials", func(t *testing.T) {
+		c, _ := openpay.NewClient("sk_invalid", srv.MerchantID, srv.Options())
+		_, err := c.Customers.Get(customer.ID)
+		if e, ok := err.(*openpay.APIError); !ok || e.Code != 1002 || e.HTTPCode != 401 {
+			t.Errorf("unexpected result: %v", err)
+		}
+	})
+
+	t.Run("Customers", func(t *testing.T) {
+		customer.PhoneNumber = "5544556677"
+		if err := client.Customers.Update(customer); err != nil {
+			t.Error(err)
+		}
+		c, err := client.Customers.Get(customer.ID)
+		if err != nil || c.PhoneNumber != customer.PhoneNumber || c.Status != "active" {
+			t.Errorf("invalid data received: %v", err)
+		}
+		list, err := client.Customers.List(&openpay.CustomersListRequest{})
+		if err != nil || len(list) != 1 {
+			t.Errorf("invalid data received: %v", err)
+		}
+		if _, err := client.Customers.Get("unknown"); err == nil {
+			t.Error("failed to detect unknown customer")
+		}
+	})
+
+	t.Run("Cards", func(t *testing.T) {
+		card := NewCard()
+		if err := client.Customers.AddCard(customer.ID, card); err != nil {
+			t.Fatal(err)
+		}
+		if card.CardNumber != "411111XXXXXX1111" || card.Brand != "visa" {
+			t.Errorf("invalid card data: %+v", card)
+		}
+		list, err := client.Customers.ListCards(customer.ID, &openpay.ListRequest{})
+		if err != nil || len(list) != 1 {
+			t.Errorf("invalid data received: %v", err)
+		}
+		if err := client.Customers.DeleteCard(customer.ID, card.ID); err != nil {
+			t.Error(err)
+		}
+		if _, err := client.Customers.GetCard(customer.ID, card.ID); err == nil {
+			t.Error("card not deleted")
+		}
+	})
+
+	t.Run("BankAccounts", func(t *testing.T) {
+		acc := &openpay.BankAccount{
+			HolderName: "Juan Hernández Sánchez",
+			Clabe:      "012298026516924616",
+		}
+		if err := client.Customers.AddBankAccount(customer.ID, acc); err != nil {
+			t.Fatal(err)
+		}
+		ba, err := client.Customers.GetBankAccount(customer.ID, acc.ID)
+		if err != nil || ba.Clabe != "012XXXXXXXXXX24616" {
+			t.Errorf("invalid data received: %v", err)
+		}
+		if err := client.Customers.DeleteBankAccount(customer.ID, acc.ID); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Charges", func(t *testing.T) {
+		card := NewCard()
+		if err := client.Charges.AddCard(card); err != nil {
+			t.Fatal(err)
+		}
+
+		// Pre-authorize, capture and refund
+		tx, err := client.Charges.WithCard(&openpay.ChargeWithStoredCard{
+			Charge: openpay.Charge{
+				Method:   "card",
+				Amount:   1000,
+				Currency: "MXN",
+				OrderID:  "order-1",
+			},
+			SourceID: card.ID,
+			Capture:  false,
+		})
+		if err != nil || tx.Status != "in_progress" {
+			t.Fatalf("unexpected result: %v", err)
+		}
+		if _, err := client.Charges.Refund(tx.ID, 1000, "not captured"); err == nil {
+			t.Error("refunded a charge not yet captured")
+		}
+		if tx, err = client.Charges.Capture(tx.ID, 1000); err != nil || tx.Status != "completed" {
+			t.Errorf("unexpected result: %v", err)
+		}
+		if tx, err = client.Charges.Refund(tx.ID, 400, "partial refund"); err != nil || tx.Status != "completed" {
+			t.Errorf("unexpected result: %v", err)
+		}
+		if tx, err = client.Charges.Refund(tx.ID, 1000, "refund"); err != nil || tx.Status != "refunded" {
+			t.Errorf("unexpected result: %v", err)
+		}
+
+		// Order identifiers must be unique
+		_, err = client.Charges.WithCard(&openpay.ChargeWithStoredCard{
+			Charge:   openpay.Charge{Method: "card", Amount: 10, OrderID: "order-1"},
+			SourceID: card.ID,
+		})
+		if e, ok := err.(*openpay.APIError); !ok || e.Code != 1006 {
+			t.Errorf("unexpected result: %v", err)
+		}
+
+		// Store charges remain in progress until paid
+		tx, err = client.Charges.AtStore(&openpay.ChargeAtStore{
+			Charge:  openpay.Charge{Method: "store", Amount: 100, Currency: "MXN"},
+			DueDate: time.Now().Add(72 * time.Hour),
+		})
+		if err != nil || tx.Status != "in_progress" {
+			t.Fatalf("unexpected result: %v", err)
+		}
+		srv.SetChargeStatus(tx.ID, "completed")
+		if tx, err = client.Charges.Get(tx.ID); err != nil || tx.Status != "completed" {
+			t.Errorf("unexpected result: %v", err)
+		}
+		list, err := client.Charges.List(&openpay.ChargesListRequest{Status: "COMPLETED"})
+		if err != nil || len(list) != 1 {
+			t.Errorf("invalid data received: %v", err)
+		}
+	})
+
+	t.Run("Webhooks", func(t *testing.T) {
+		hook := &openpay.Webhook{
+			URL:        "https://example.com/hooks",
+			EventTypes: []openpay.EventType{openpay.EventChargeSucceeded},
+		}
+		if err := client.Webhooks.Create(hook); err != nil {
+			t.Fatal(err)
+		}
+		if hook.Status != "verified" {
+			t.Error("invalid data received")
+		}
+		list, err := client.Webhooks.List()
+		if err != nil || len(list) != 1 {
+			t.Errorf("invalid data received: %v", err)
+		}
+		if err := client.Webhooks.Delete(hook.ID); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestServerTestCards(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	for _, tc := range openpay.SandboxCards {
+		t.Run(tc.Number, func(t *testing.T) {
+			cvv := "401"
+			if tc.Brand == openpay.BrandAmericanExpress {
+				cvv = "4010"
+			}
+			card := NewCard()
+			card.CardNumber = tc.Number
+			card.CVV2 = cvv
+			if err := client.Charges.AddCard(card); err != nil {
+				t.Fatal(err)
+			}
+			_, err := client.Charges.WithCard(&openpay.ChargeWithStoredCard{
+				Charge:   openpay.Charge{Method: "card", Amount: 100, Currency: "MXN"},
+				SourceID: card.ID,
+				Capture:  true,
+			})
+			if tc.ErrorCode == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			e, ok := err.(*openpay.APIError)
+			if !ok || e.Code != tc.ErrorCode || e.HTTPCode != 402 || len(e.FraudRules) != len(tc.FraudRules) {
+				t.Errorf("unexpected result: %v", err)
+			}
+		})
+	}
+}
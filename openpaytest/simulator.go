@@ -0,0 +1,283 @@
+// Package openpaytest provides utilities to exercise code built on top of the
+// openpay client without access to the real service.
+package openpaytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/fairbank-io/openpay"
+)
+
+// Simulator delivers webhook events to a local destination, using the same
+// format and credentials used by the service
+type Simulator struct {
+	// Destination URL, used when no handler is provided
+	URL string
+
+	// Destination handler, takes precedence over URL
+	Handler http.Handler
+
+	// Username value for basic credentials, if any
+	User string
+
+	// Password value for basic credentials, if any
+	Password string
+
+	// HTTP client used to deliver events to URL, http.DefaultClient if nil
+	Client *http.Client
+}
+
+// NewEvent builds a realistic event of the provided type. Non-empty fields on
+// 'tx', if provided, will override the generated transaction values.
+func NewEvent(eventType openpay.EventType, tx *openpay.Transaction) (*openpay.Event, error) {
+	base, ok := eventTransaction(eventType)
+	if !ok {
+		return nil, fmt.Errorf("unknown event type: %s", eventType)
+	}
+
+	if tx != nil {
+		overrideTransaction(base, tx)
+	}
+	return &openpay.Event{
+		Type:        eventType,
+		EventDate:   time.Now().UTC().Truncate(time.Second),
+		Transaction: base,
+	}, nil
+}
+
+// Apply the non-zero fields of 'tx' on top of the generated transaction
+func overrideTransaction(base, tx *openpay.Transaction) {
+	if tx.ID != "" {
+		base.ID = tx.ID
+	}
+	if tx.Authorization != "" {
+		base.Authorization = tx.Authorization
+	}
+	if tx.TransactionType != "" {
+		base.TransactionType = tx.TransactionType
+	}
+	if tx.OperationType != "" {
+		base.OperationType = tx.OperationType
+	}
+	if tx.OrderID != "" {
+		base.OrderID = tx.OrderID
+	}
+	if tx.CustomerID != "" {
+		base.CustomerID = tx.CustomerID
+	}
+	if tx.Amount != 0 {
+		base.Amount = tx.Amount
+	}
+	if tx.Currency != "" {
+		base.Currency = tx.Currency
+	}
+	if tx.Method != "" {
+		base.Method = tx.Method
+	}
+	if !tx.CreationDate.IsZero() {
+		base.CreationDate = tx.CreationDate
+	}
+	if !tx.DueDate.IsZero() {
+		base.DueDate = tx.DueDate
+	}
+	if tx.Status != "" {
+		base.Status = tx.Status
+	}
+	if tx.ErrorMessage != "" {
+		base.ErrorMessage = tx.ErrorMessage
+	}
+	if tx.Description != "" {
+		base.Description = tx.Description
+	}
+	if tx.BankAccount != nil {
+		base.BankAccount = tx.BankAccount
+	}
+	if tx.Card != nil {
+		base.Card = tx.Card
+	}
+	if tx.CardPoints != nil {
+		base.CardPoints = tx.CardPoints
+	}
+}
+
+// Send builds and delivers a new event of the provided type, see NewEvent
+func (s *Simulator) Send(eventType openpay.EventType, tx *openpay.Transaction) (*openpay.Event, error) {
+	ev, err := NewEvent(eventType, tx)
+	if err != nil {
+		return nil, err
+	}
+	return ev, s.Deliver(ev)
+}
+
+// Verify delivers the verification event sent by the service when a webhook is
+// registered
+func (s *Simulator) Verify(code string) error {
+	return s.Deliver(&openpay.Event{
+		Type:             openpay.EventVerification,
+		EventDate:        time.Now().UTC().Truncate(time.Second),
+		VerificationCode: code,
+	})
+}
+
+// Deliver posts the event to the configured destination. An error is returned
+// if the destination doesn't respond with a successful status code.
+func (s *Simulator) Deliver(ev *openpay.Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	target := s.URL
+	if s.Handler != nil || target == "" {
+		target = "http://localhost/"
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.User != "" || s.Password != "" {
+		req.SetBasicAuth(s.User, s.Password)
+	}
+
+	// Deliver to the local handler
+	if s.Handler != nil {
+		rec := httptest.NewRecorder()
+		s.Handler.ServeHTTP(rec, req)
+		return checkDelivery(ev, rec.Code)
+	}
+	if s.URL == "" {
+		return errors.New("no destination URL or handler provided")
+	}
+
+	// Deliver over the network
+	c := s.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	return checkDelivery(ev, res.StatusCode)
+}
+
+// Delivery is considered successful on any 2xx status code
+func checkDelivery(ev *openpay.Event, code int) error {
+	if code < 200 || code > 299 {
+		return fmt.Errorf("event %s rejected with status code %d", ev.Type, code)
+	}
+	return nil
+}
+
+// Returns a transaction with values consistent with the event type
+func eventTransaction(eventType openpay.EventType) (*openpay.Transaction, bool) {
+	tx := &openpay.Transaction{
+		ID:              randomID(),
+		Authorization:   fmt.Sprintf("%06d", rand.Intn(1000000)),
+		TransactionType: openpay.TransactionCharge,
+		OperationType:   openpay.OperationIn,
+		OrderID:         "oid-" + randomID()[:8],
+		Amount:          100,
+		Currency:        openpay.CurrencyMXN,
+		Method:          openpay.MethodCard,
+		CreationDate:    time.Now().UTC().Truncate(time.Second),
+		Status:          openpay.StatusCompleted,
+		Description:     "simulated transaction",
+	}
+	card := &openpay.Card{
+		HolderName:      "Juan Perez Ramirez",
+		CardNumber:      "411111XXXXXX1111",
+		ExpirationMonth: "12",
+		ExpirationYear:  ExpirationYear(),
+		Brand:           openpay.BrandVisa,
+		Type:            openpay.CardDebit,
+		BankName:        "Banamex",
+		BankCode:        "002",
+		AllowsCharges:   true,
+		AllowsPayouts:   true,
+	}
+	account := &openpay.BankAccount{
+		HolderName: "Juan Perez Ramirez",
+		Clabe:      "012XXXXXXXXXX24616",
+		BankName:   "BBVA BANCOMER",
+		BankCode:   "012",
+	}
+
+	switch eventType {
+	case openpay.EventChargeSucceeded, openpay.EventOrderPaymentReceived, openpay.EventOrderCompleted:
+		tx.Card = card
+	case openpay.EventChargeCreated, openpay.EventOrderCreated, openpay.EventOrderActivated:
+		tx.Status = openpay.StatusInProgress
+		tx.Card = card
+	case openpay.EventChargeFailed, openpay.EventChargeRescoredToDecline, openpay.EventSubscriptionChargeFailed:
+		tx.Status = openpay.StatusFailed
+		tx.ErrorMessage = "The card was declined"
+		tx.Card = card
+	case openpay.EventChargeCancelled, openpay.EventOrderExpired, openpay.EventOrderCancelled, openpay.EventOrderPaymentCancelled:
+		tx.Status = openpay.StatusCancelled
+		tx.Method = openpay.MethodStore
+	case openpay.EventChargeRefunded:
+		tx.Status = openpay.StatusRefunded
+		tx.Card = card
+	case openpay.EventChargebackCreated:
+		tx.Status = openpay.StatusChargebackPending
+		tx.Card = card
+	case openpay.EventChargebackAccepted:
+		tx.Status = openpay.StatusChargebackAccepted
+		tx.Card = card
+	case openpay.EventChargebackRejected:
+		tx.Card = card
+	case openpay.EventSpeiReceived:
+		tx.Method = openpay.MethodBankAccount
+		tx.BankAccount = account
+	case openpay.EventPayoutCreated, openpay.EventPayoutSucceeded, openpay.EventPayoutFailed:
+		tx.TransactionType = openpay.TransactionPayout
+		tx.OperationType = openpay.OperationOut
+		tx.Method = openpay.MethodBankAccount
+		tx.BankAccount = account
+		if eventType == openpay.EventPayoutCreated {
+			tx.Status = openpay.StatusInProgress
+		}
+		if eventType == openpay.EventPayoutFailed {
+			tx.Status = openpay.StatusFailed
+			tx.ErrorMessage = "The bank account was rejected"
+		}
+	case openpay.EventTransferSucceeded:
+		tx.TransactionType = openpay.TransactionTransfer
+		tx.OperationType = openpay.OperationOut
+		tx.Method = openpay.MethodCustomer
+	case openpay.EventFeeSucceeded:
+		tx.TransactionType = openpay.TransactionFee
+		tx.Method = openpay.MethodCustomer
+	case openpay.EventFeeRefundSucceeded:
+		tx.TransactionType = openpay.TransactionFee
+		tx.OperationType = openpay.OperationOut
+		tx.Method = openpay.MethodCustomer
+	default:
+		return nil, false
+	}
+	return tx, true
+}
+
+// Generate an identifier using the same format as the service
+func randomID() string {
+	const chars = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, 20)
+	b[0] = chars[rand.Intn(26)]
+	for i := 1; i < len(b); i++ {
+		b[i] = chars[rand.Intn(len(chars))]
+	}
+	return string(b)
+}
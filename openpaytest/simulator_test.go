@@ -0,0 +1,62 @@
+package openpaytest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/fairbank-io/openpay"
+)
+
+func TestSimulator(t *testing.T) {
+	var received []openpay.Event
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "foo" || pass != "bar" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		ev := openpay.Event{}
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, ev)
+	})
+
+	sim := &Simulator{Handler: handler, User: "foo", Password: "bar"}
+	for _, et := range openpay.AllEvents() {
+		if _, err := sim.Send(et, &openpay.Transaction{OrderID: "order-1", Amount: 42}); err != nil {
+			t.Error(err)
+		}
+	}
+	if len(received) != len(openpay.AllEvents()) {
+		t.Fatalf("expected %d events, got %d", len(openpay.AllEvents()), len(received))
+	}
+	for _, ev := range received {
+		if ev.Transaction == nil || ev.Transaction.ID == "" {
+			t.Errorf("%s: missing transaction details", ev.Type)
+			continue
+		}
+		if ev.Transaction.OrderID != "order-1" || ev.Transaction.Amount != 42 {
+			t.Errorf("%s: overrides not applied", ev.Type)
+		}
+	}
+
+	// Wrong credentials must be reported
+	sim.Password = "invalid"
+	if _, err := sim.Send(openpay.EventChargeSucceeded, nil); err == nil {
+		t.Error("failed to detect rejected delivery")
+	}
+
+	// Generated values not overridden are preserved
+	ev, _ := NewEvent(openpay.EventChargeSucceeded, &openpay.Transaction{Status: openpay.StatusCompleted})
+	if ev.Transaction.CreationDate.IsZero() || ev.Transaction.ID == "" || ev.Transaction.Status != openpay.StatusCompleted {
+		t.Errorf("invalid transaction: %+v", ev.Transaction)
+	}
+
+	// Unknown event types
+	if _, err := NewEvent("charge.unknown", nil); err == nil {
+		t.Error("failed to detect invalid event type")
+	}
+}
@@ -0,0 +1,143 @@
+package openpay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownMerchant is returned when requesting a client for a merchant not
+// registered in a pool
+var ErrUnknownMerchant = errors.New("unknown merchant")
+
+// Pool manages clients for several merchant accounts, for example on
+// marketplace platforms. All clients share the same configuration options and
+// HTTP transport, so network connections are reused across merchants.
+// Merchants can be added, removed and have their keys rotated at any time; a
+// pool is safe for concurrent use.
+type Pool struct {
+	options *Options
+	hc      *http.Client
+	mu      sync.RWMutex
+	clients map[string]*Client
+}
+
+// Context key used to store the merchant for a request
+type merchantKey struct{}
+
+// NewPool returns an empty pool using the provided configuration options for
+// all its clients, if 'nil' options are provided default sane values will be
+// used; the same applies to any setting not provided
+func NewPool(options *Options) *Pool {
+	if options == nil {
+		options = defaultOptions()
+	}
+	options = options.withDefaults()
+	return &Pool{
+		options: options,
+		hc:      newHTTPClient(options),
+		clients: make(map[string]*Client),
+	}
+}
+
+// Add registers a merchant account in the pool
+func (p *Pool) Add(key, merchantID string) error {
+	if key == "" {
+		return errors.New("API key is required")
+	}
+	c, err := newClient(StaticKey(key), merchantID, p.options, p.hc)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.clients[merchantID]; ok {
+		return fmt.Errorf("merchant %s already registered", merchantID)
+	}
+	p.clients[merchantID] = c
+	return nil
+}
+
+// Remove a merchant account from the pool; requests already in progress for
+// the merchant are not affected
+func (p *Pool) Remove(merchantID string) {
+	p.mu.Lock()
+	delete(p.clients, merchantID)
+	p.mu.Unlock()
+}
+
+// Rotate replaces the API key used for a registered merchant; requests already
+// in progress complete using the previous key
+func (p *Pool) Rotate(merchantID, key string) error {
+	if key == "" {
+		return errors.New("API key is required")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	current, ok := p.clients[merchantID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownMerchant, merchantID)
+	}
+	c := *current
+	c.creds = StaticKey(key)
+	if current.verified != nil {
+		// The new key is verified again before its first request
+		c.verified = &verification{}
+	}
+	c.bind()
+	p.clients[merchantID] = &c
+	return nil
+}
+
+// Merchants returns the identifiers of all registered merchants, sorted
+func (p *Pool) Merchants() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	list := make([]string, 0, len(p.clients))
+	for id := range p.clients {
+		list = append(list, id)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Client returns the handler for a registered merchant
+func (p *Pool) Client(merchantID string) (*Client, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	c, ok := p.clients[merchantID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMerchant, merchantID)
+	}
+	return c, nil
+}
+
+// FromContext returns the handler for the merchant stored in the context
+// using 'WithMerchant'; the handler uses the provided context for all its
+// requests
+func (p *Pool) FromContext(ctx context.Context) (*Client, error) {
+	merchantID, ok := MerchantFromContext(ctx)
+	if !ok {
+		return nil, errors.New("no merchant in context")
+	}
+	c, err := p.Client(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	return c.WithContext(ctx), nil
+}
+
+// WithMerchant returns a copy of the context holding the merchant to use
+// when getting a handler from a pool
+func WithMerchant(ctx context.Context, merchantID string) context.Context {
+	return context.WithValue(ctx, merchantKey{}, merchantID)
+}
+
+// MerchantFromContext returns the merchant stored in the context, if any
+func MerchantFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(merchantKey{}).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,91 @@
+package openpay_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
+)
+
+func TestPool(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+
+	pool := openpay.NewPool(srv.Options())
+	if err := pool.Add(srv.Key, srv.MerchantID); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Add("sk_other", "mother"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Add(srv.Key, srv.MerchantID); err == nil {
+		t.Error("duplicated merchants should be rejected")
+	}
+	if !reflect.DeepEqual(pool.Merchants(), []string{"mother", srv.MerchantID}) {
+		t.Errorf("unexpected merchants: %v", pool.Merchants())
+	}
+
+	// Select merchant from context
+	client, err := pool.FromContext(openpay.WithMerchant(context.Background(), srv.MerchantID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Webhooks.List(); err != nil {
+		t.Error(err)
+	}
+	other, _ := pool.FromContext(openpay.WithMerchant(context.Background(), "mother"))
+	if _, err := other.Webhooks.List(); err == nil {
+		t.Error("each merchant should use its own credentials")
+	}
+	if _, err := pool.FromContext(context.Background()); err == nil {
+		t.Error("expected error for missing merchant")
+	}
+
+	// Rotate keys
+	if err := pool.Rotate(srv.MerchantID, "sk_revoked"); err != nil {
+		t.Fatal(err)
+	}
+	client, _ = pool.Client(srv.MerchantID)
+	if _, err := client.Webhooks.List(); err == nil {
+		t.Error("rotated key should be used")
+	}
+	pool.Rotate(srv.MerchantID, srv.Key)
+	client, _ = pool.Client(srv.MerchantID)
+	if _, err := client.Webhooks.List(); err != nil {
+		t.Error(err)
+	}
+
+	// Remove merchants
+	pool.Remove("mother")
+	if _, err := pool.Client("mother"); !errors.Is(err, openpay.ErrUnknownMerchant) {
+		t.Errorf("expected unknown merchant error, got %v", err)
+	}
+	if err := pool.Rotate("mother", "sk_other"); !errors.Is(err, openpay.ErrUnknownMerchant) {
+		t.Errorf("expected unknown merchant error, got %v", err)
+	}
+}
+
+func TestPoolRotateVerification(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+
+	opts := srv.Options()
+	opts.VerifyMerchant = true
+	pool := openpay.NewPool(opts)
+	pool.Add(srv.Key, srv.MerchantID)
+	client, _ := pool.Client(srv.MerchantID)
+	if _, err := client.Webhooks.List(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotated keys are verified again
+	pool.Rotate(srv.MerchantID, "sk_revoked")
+	client, _ = pool.Client(srv.MerchantID)
+	var envErr *openpay.EnvironmentError
+	if _, err := client.Webhooks.List(); !errors.As(err, &envErr) {
+		t.Errorf("expected environment error, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package openpay
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit defines a token bucket used to pace requests
+type RateLimit struct {
+	// Sustained number of requests allowed per second, unlimited if 0
+	Rate float64
+
+	// Maximum number of requests allowed at once, 1 if not provided
+	Burst int
+}
+
+// RateLimits control the pace of requests sent to the service, to avoid
+// being throttled when a client is shared by many tasks. Requests are grouped
+// in classes with independent limits:
+//   - charges: operations moving funds, i.e. creating, capturing and refunding
+//     charges
+//   - reads: all other GET requests
+//   - writes: all other requests
+type RateLimits struct {
+	Reads   RateLimit
+	Writes  RateLimit
+	Charges RateLimit
+
+	// Maximum number of requests in progress at the same time, unlimited if 0
+	MaxConcurrent int
+
+	// Time to stop sending requests of a given class after the service
+	// responds with a 429 status code without a 'Retry-After' header, 1
+	// second if not provided
+	Backoff time.Duration
+}
+
+// Request classes with independent limits
+const (
+	classReads = iota
+	classWrites
+	classCharges
+)
+
+// Client side requests limiter
+type limiter struct {
+	buckets [3]*bucket
+	sem     chan struct{}
+	backoff time.Duration
+}
+
+// Token bucket for a single request class
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	paused time.Time
+}
+
+// Return a limiter for the provided settings
+func newLimiter(rl *RateLimits) *limiter {
+	l := &limiter{backoff: rl.Backoff}
+	if l.backoff == 0 {
+		l.backoff = time.Second
+	}
+	if rl.MaxConcurrent > 0 {
+		l.sem = make(chan struct{}, rl.MaxConcurrent)
+	}
+	for i, r := range []RateLimit{rl.Reads, rl.Writes, rl.Charges} {
+		burst := float64(r.Burst)
+		if burst < 1 {
+			burst = 1
+		}
+		l.buckets[i] = &bucket{rate: r.Rate, burst: burst, tokens: burst, last: time.Now()}
+	}
+	return l
+}
+
+// Get the class used to limit a request
+func (r *requestOptions) class() int {
+	switch {
+	case r.method == http.MethodGet:
+		return classReads
+	case strings.HasPrefix(r.operation, "charges."):
+		return classCharges
+	default:
+		return classWrites
+	}
+}
+
+// Wait until the request can be sent; the returned function must be called
+// once the request is completed to release its concurrency slot. A nil limiter
+// never blocks.
+func (l *limiter) acquire(ctx context.Context, r *requestOptions) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	if err := l.buckets[r.class()].wait(ctx); err != nil {
+		return nil, err
+	}
+	if l.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return func() { <-l.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Adjust the limits based on the service response; throttled requests pause
+// all requests of the same class
+func (l *limiter) feedback(r *requestOptions, res *result) {
+	if l == nil || res.status != http.StatusTooManyRequests {
+		return
+	}
+	delay := l.backoff
+	if secs, err := strconv.Atoi(res.header.Get("Retry-After")); err == nil && secs > 0 {
+		delay = time.Duration(secs) * time.Second
+	}
+	l.buckets[r.class()].pause(delay)
+}
+
+// Block until a token is available or the context is done
+func (b *bucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		var delay time.Duration
+		switch {
+		case now.Before(b.paused):
+			delay = b.paused.Sub(now)
+		case b.rate == 0:
+			b.mu.Unlock()
+			return nil
+		default:
+			b.tokens += now.Sub(b.last).Seconds() * b.rate
+			if b.tokens > b.burst {
+				b.tokens = b.burst
+			}
+			b.last = now
+			if b.tokens >= 1 {
+				b.tokens--
+				b.mu.Unlock()
+				return nil
+			}
+			delay = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		}
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Stop handing out tokens for the provided time
+func (b *bucket) pause(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	until := time.Now().Add(d)
+	if until.After(b.paused) {
+		b.paused = until
+	}
+	b.tokens = 0
+	b.last = until
+}
@@ -0,0 +1,116 @@
+package openpay_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
+)
+
+func TestRateLimits(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+
+	t.Run("Rate", func(t *testing.T) {
+		opts := srv.Options()
+		opts.RateLimits = &openpay.RateLimits{
+			Reads: openpay.RateLimit{Rate: 20, Burst: 2},
+		}
+		client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			if _, err := client.Webhooks.List(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+			t.Errorf("requests not limited, took %s", elapsed)
+		}
+
+		// Other classes are not affected
+		start = time.Now()
+		for i := 0; i < 4; i++ {
+			client.Webhooks.Delete("unknown")
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("writes should not be limited, took %s", elapsed)
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		opts := srv.Options()
+		opts.RateLimits = &openpay.RateLimits{
+			Charges: openpay.RateLimit{Rate: 0.1},
+		}
+		client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+		client.Charges.Capture("unknown", 0)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := client.WithContext(ctx).Charges.Refund("unknown", 0, "")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline error, got %v", err)
+		}
+	})
+
+	t.Run("Throttled", func(t *testing.T) {
+		opts := srv.Options()
+		opts.RateLimits = &openpay.RateLimits{Backoff: 100 * time.Millisecond}
+		client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+		srv.Inject(openpaytest.Fault{Path: "webhooks", Times: 1, Status: 429})
+		defer srv.ClearFaults()
+
+		start := time.Now()
+		if _, err := client.Webhooks.List(); err == nil {
+			t.Fatal("expected error")
+		}
+		if _, err := client.Webhooks.List(); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+			t.Errorf("throttled requests should pause the limiter, took %s", elapsed)
+		}
+	})
+
+	t.Run("MaxConcurrent", func(t *testing.T) {
+		var mu sync.Mutex
+		var current, peak int
+		opts := srv.Options()
+		opts.RateLimits = &openpay.RateLimits{MaxConcurrent: 2}
+		opts.Observers = []openpay.Observer{openpay.ObserverFuncs{
+			OnStart: func(*openpay.RequestInfo) {
+				mu.Lock()
+				current++
+				if current > peak {
+					peak = current
+				}
+				mu.Unlock()
+			},
+			OnFinish: func(*openpay.RequestInfo) {
+				mu.Lock()
+				current--
+				mu.Unlock()
+			},
+		}}
+		client, _ := openpay.NewClient(srv.Key, srv.MerchantID, opts)
+		srv.Inject(openpaytest.Fault{Path: "webhooks", Latency: 20 * time.Millisecond})
+		defer srv.ClearFaults()
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				client.Webhooks.List()
+			}()
+		}
+		wg.Wait()
+		if peak != 2 {
+			t.Errorf("expected at most 2 concurrent requests, got %d", peak)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package openpay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Describes the changes required to bring the webhooks registered on the
+// service in line with a desired configuration
+type WebhooksPlan struct {
+	// Webhooks to be registered
+	Create []Webhook
+
+	// Registered webhooks to be removed, either stale, duplicated or outdated
+	Delete []Webhook
+
+	// Registered webhooks already matching a desired definition
+	Keep []Webhook
+}
+
+// Reconciliation settings
+type ReconcileOptions struct {
+	// Only compute and report the changes, without applying them
+	DryRun bool
+
+	// If provided, planned changes will be printed here before being applied
+	Output io.Writer
+}
+
+// Empty returns true if no changes are required
+func (p *WebhooksPlan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Delete) == 0
+}
+
+// Returns a human readable summary of the planned changes
+func (p *WebhooksPlan) String() string {
+	b := &bytes.Buffer{}
+	for _, wh := range p.Create {
+		fmt.Fprintf(b, "+ create %s %v\n", wh.URL, webhookEvents(wh))
+	}
+	for _, wh := range p.Delete {
+		fmt.Fprintf(b, "- delete %s %s %v\n", wh.ID, wh.URL, webhookEvents(wh))
+	}
+	for _, wh := range p.Keep {
+		fmt.Fprintf(b, "= keep   %s %s %v\n", wh.ID, wh.URL, webhookEvents(wh))
+	}
+	if p.Empty() {
+		b.WriteString("no changes required\n")
+	}
+	return b.String()
+}
+
+// PlanWebhooks compares the webhooks currently registered with the desired set
+// and returns the changes required to reconcile them. Webhooks are considered
+// equivalent when URL, user and the set of event types match; since the
+// service doesn't report passwords they are not taken into account.
+func PlanWebhooks(api WebhooksAPI, desired []Webhook) (*WebhooksPlan, error) {
+	current, err := api.List()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &WebhooksPlan{}
+	claimed := make([]bool, len(current))
+	seen := make(map[string]bool)
+	for _, wh := range desired {
+		// Ignore repeated desired definitions
+		k := webhookKey(wh)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		// Keep the first matching registered instance, if any
+		found := false
+		for i, c := range current {
+			if !claimed[i] && webhookKey(c) == k {
+				claimed[i] = true
+				plan.Keep = append(plan.Keep, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			plan.Create = append(plan.Create, wh)
+		}
+	}
+
+	// Everything not claimed is either stale or a duplicate
+	for i, c := range current {
+		if !claimed[i] {
+			plan.Delete = append(plan.Delete, c)
+		}
+	}
+	return plan, nil
+}
+
+// ReconcileWebhooks computes the changes required to match the desired set of
+// webhooks and applies them. New webhooks are registered before removing old ones
+// to avoid missing events during the process. The computed plan is returned
+// even when applying it fails.
+func ReconcileWebhooks(api WebhooksAPI, desired []Webhook, opts *ReconcileOptions) (*WebhooksPlan, error) {
+	if opts == nil {
+		opts = &ReconcileOptions{}
+	}
+
+	plan, err := PlanWebhooks(api, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Output != nil {
+		if opts.DryRun {
+			fmt.Fprint(opts.Output, "dry run, no changes will be applied\n")
+		}
+		fmt.Fprint(opts.Output, plan.String())
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	for i := range plan.Create {
+		if err := api.Create(&plan.Create[i]); err != nil {
+			return plan, fmt.Errorf("create webhook %s: %w", plan.Create[i].URL, err)
+		}
+	}
+	for _, wh := range plan.Delete {
+		if err := api.Delete(wh.ID); err != nil {
+			return plan, fmt.Errorf("delete webhook %s: %w", wh.ID, err)
+		}
+	}
+	return plan, nil
+}
+
+// Returns the sorted and de-duplicated list of event types for a webhook
+func webhookEvents(wh Webhook) []string {
+	set := make(map[string]bool)
+	var list []string
+	for _, e := range wh.EventTypes {
+		if !set[string(e)] {
+			set[string(e)] = true
+			list = append(list, string(e))
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Identity used to compare webhook definitions
+func webhookKey(wh Webhook) string {
+	return strings.Join([]string{wh.URL, wh.User, strings.Join(webhookEvents(wh), ",")}, "|")
+}
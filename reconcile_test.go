@@ -0,0 +1,86 @@
+package openpay
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Minimal in-memory 'webhooks' implementation
+type webhooksStub struct {
+	list    []Webhook
+	counter int
+}
+
+func (ws *webhooksStub) Create(wh *Webhook) error {
+	ws.counter++
+	wh.ID = fmt.Sprintf("wh%d", ws.counter)
+	ws.list = append(ws.list, *wh)
+	return nil
+}
+
+func (ws *webhooksStub) Get(whID string) (*Webhook, error) {
+	for _, wh := range ws.list {
+		if wh.ID == whID {
+			return &wh, nil
+		}
+	}
+	return nil, &APIError{Code: 1005, HTTPCode: 404}
+}
+
+func (ws *webhooksStub) List() ([]Webhook, error) {
+	return append([]Webhook(nil), ws.list...), nil
+}
+
+func (ws *webhooksStub) Delete(whID string) error {
+	for i, wh := range ws.list {
+		if wh.ID == whID {
+			ws.list = append(ws.list[:i], ws.list[i+1:]...)
+			return nil
+		}
+	}
+	return &APIError{Code: 1005, HTTPCode: 404}
+}
+
+func TestReconcileWebhooks(t *testing.T) {
+	api := &webhooksStub{}
+	api.Create(&Webhook{URL: "https://example.com/hooks", EventTypes: []EventType{EventSpeiReceived, EventChargeSucceeded}})
+	api.Create(&Webhook{URL: "https://example.com/hooks", EventTypes: []EventType{EventChargeSucceeded, EventSpeiReceived}})
+	api.Create(&Webhook{URL: "https://example.com/stale", EventTypes: []EventType{EventChargeFailed}})
+
+	desired := []Webhook{
+		{URL: "https://example.com/hooks", EventTypes: []EventType{EventChargeSucceeded, EventSpeiReceived}},
+		{URL: "https://example.com/payouts", EventTypes: []EventType{EventPayoutSucceeded}},
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		plan, err := ReconcileWebhooks(api, desired, &ReconcileOptions{DryRun: true, Output: out})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plan.Create) != 1 || len(plan.Delete) != 2 || len(plan.Keep) != 1 {
+			t.Errorf("unexpected plan: %s", plan)
+		}
+		if !strings.Contains(out.String(), "+ create https://example.com/payouts") {
+			t.Errorf("planned changes not reported: %s", out)
+		}
+		if len(api.list) != 3 {
+			t.Error("dry run should not apply changes")
+		}
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		if _, err := ReconcileWebhooks(api, desired, nil); err != nil {
+			t.Fatal(err)
+		}
+		plan, err := PlanWebhooks(api, desired)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !plan.Empty() || len(plan.Keep) != 2 {
+			t.Errorf("webhooks not reconciled: %s", plan)
+		}
+	})
+}
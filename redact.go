@@ -0,0 +1,388 @@
+package openpay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Value used in place of fully hidden data
+const redactedValue = "[REDACTED]"
+
+// Redactor scrubs sensitive values from JSON documents, for example request
+// and response bodies before logging or storing them
+type Redactor struct {
+	// Fields masked using the provided function, for example
+	// 'MaskCardNumber'; numbers are masked using their text representation
+	// and any other non-null value is fully hidden
+	Mask map[string]func(string) string
+
+	// Fields fully hidden, whatever their type
+	Hide []string
+}
+
+// DefaultRedactor masks card numbers and CLABEs, and hides security codes and
+// passwords
+var DefaultRedactor = &Redactor{
+	Mask: map[string]func(string) string{
+		"card_number": MaskCardNumber,
+		"clabe":       maskClabe,
+	},
+	Hide: []string{"cvv2", "password"},
+}
+
+// RedactJSON scrubs sensitive values from a JSON document using the default
+// redactor
+func RedactJSON(data []byte) []byte {
+	return DefaultRedactor.JSON(data)
+}
+
+// JSON returns a copy of the document with all sensitive fields scrubbed, at
+// any nesting level. Content that is not valid JSON is returned as-is.
+func (r *Redactor) JSON(data []byte) []byte {
+	// Numbers are decoded as text to preserve all their digits
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if len(data) == 0 || dec.Decode(&v) != nil || dec.Decode(new(interface{})) != io.EOF {
+		return data
+	}
+	b, err := json.Marshal(r.value(v))
+	if err != nil {
+		return data
+	}
+	return b
+}
+
+func (r *Redactor) value(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			mask, masked := r.Mask[k]
+			switch {
+			case val == nil || val == "":
+			case contains(r.Hide, k):
+				t[k] = redactedValue
+			case masked:
+				switch m := val.(type) {
+				case string:
+					t[k] = mask(m)
+				case json.Number:
+					t[k] = mask(m.String())
+				default:
+					t[k] = redactedValue
+				}
+			default:
+				t[k] = r.value(val)
+			}
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = r.value(t[i])
+		}
+	}
+	return v
+}
+
+// MaskCardNumber hides all but the first 6 and last 4 digits of a card number,
+// using the same format as the service. Short values are fully hidden.
+func MaskCardNumber(number string) string {
+	if number == "" {
+		return ""
+	}
+	if len(number) < 12 {
+		return strings.Repeat("X", len(number))
+	}
+	return number[:6] + strings.Repeat("X", len(number)-10) + number[len(number)-4:]
+}
+
+// Return a safe hidden representation of a sensitive value
+func hide(v string) string {
+	if v == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+func contains(list []string, v string) bool {
+	for _, i := range list {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Card type without custom formatting, used to print masked copies
+type plainCard Card
+
+// Returns a copy of the card with sensitive values masked
+func (c Card) masked() plainCard {
+	c.CardNumber = MaskCardNumber(c.CardNumber)
+	c.CVV2 = hide(c.CVV2)
+	return plainCard(c)
+}
+
+// Format implements 'fmt.Formatter', card number and security code are
+// always masked
+func (c Card) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), c.masked())
+}
+
+// Returns a text representation with sensitive values masked
+func (c Card) String() string {
+	return fmt.Sprintf("%+v", c.masked())
+}
+
+// LogValue implements 'slog.LogValuer', card number and security code are
+// always masked
+func (c Card) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", c.ID),
+		slog.String("holder_name", c.HolderName),
+		slog.String("card_number", MaskCardNumber(c.CardNumber)),
+		slog.String("cvv2", hide(c.CVV2)),
+		slog.String("expiration_month", c.ExpirationMonth),
+		slog.String("expiration_year", c.ExpirationYear),
+		slog.String("brand", string(c.Brand)),
+		slog.String("customer_id", c.CustomerID),
+	)
+}
+
+// Charge type without custom formatting, used to print masked copies; the
+// base charge is embedded using its plain type, otherwise its formatting
+// methods would be promoted and used for the whole value
+type plainChargeWithStoredCard struct {
+	plainCharge
+	SourceID        string
+	CVV2            string
+	DeviceSessionID string
+	Capture         bool
+	UseCardPoints   CardPointsUsage
+	Use3DSecure     bool
+	PaymentPlan     *PaymentPlan
+	Metadata        map[string]string
+}
+
+// Returns a copy of the charge with sensitive values masked
+func (c ChargeWithStoredCard) masked() plainChargeWithStoredCard {
+	return plainChargeWithStoredCard{
+		plainCharge:     c.Charge.masked(),
+		SourceID:        c.SourceID,
+		CVV2:            hide(c.CVV2),
+		DeviceSessionID: c.DeviceSessionID,
+		Capture:         c.Capture,
+		UseCardPoints:   c.UseCardPoints,
+		Use3DSecure:     c.Use3DSecure,
+		PaymentPlan:     c.PaymentPlan,
+		Metadata:        c.Metadata,
+	}
+}
+
+// Format implements 'fmt.Formatter', the card security code is always hidden
+func (c ChargeWithStoredCard) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), c.masked())
+}
+
+// Returns a text representation with sensitive values masked
+func (c ChargeWithStoredCard) String() string {
+	return fmt.Sprintf("%+v", c.masked())
+}
+
+// LogValue implements 'slog.LogValuer', the card security code is always hidden
+func (c ChargeWithStoredCard) LogValue() slog.Value {
+	return slog.GroupValue(append(c.attrs(),
+		slog.String("source_id", c.SourceID),
+		slog.String("cvv2", hide(c.CVV2)),
+		slog.Bool("capture", c.Capture),
+	)...)
+}
+
+// Hide all but the bank code and last 5 digits of a CLABE, using the same
+// format as the service
+func maskClabe(number string) string {
+	if len(number) < 12 {
+		return strings.Repeat("X", len(number))
+	}
+	return number[:3] + strings.Repeat("X", len(number)-8) + number[len(number)-5:]
+}
+
+// Bank account type without custom formatting, used to print masked copies
+type plainBankAccount BankAccount
+
+// Returns a copy of the account with sensitive values masked
+func (acc BankAccount) masked() plainBankAccount {
+	acc.Clabe = maskClabe(acc.Clabe)
+	return plainBankAccount(acc)
+}
+
+// Format implements 'fmt.Formatter', the CLABE is always masked
+func (acc BankAccount) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), acc.masked())
+}
+
+// Returns a text representation with sensitive values masked
+func (acc BankAccount) String() string {
+	return fmt.Sprintf("%+v", acc.masked())
+}
+
+// LogValue implements 'slog.LogValuer', the CLABE is always masked
+func (acc BankAccount) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", acc.ID),
+		slog.String("holder_name", acc.HolderName),
+		slog.String("clabe", maskClabe(acc.Clabe)),
+		slog.String("bank_code", acc.BankCode),
+	)
+}
+
+// Customer type without custom formatting, used to print masked copies
+type plainCustomer Customer
+
+// Returns a copy of the customer with sensitive values masked
+func (c Customer) masked() plainCustomer {
+	c.Clabe = maskClabe(c.Clabe)
+	return plainCustomer(c)
+}
+
+// Format implements 'fmt.Formatter', the CLABE is always masked
+func (c Customer) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), c.masked())
+}
+
+// Returns a text representation with sensitive values masked
+func (c Customer) String() string {
+	return fmt.Sprintf("%+v", c.masked())
+}
+
+// LogValue implements 'slog.LogValuer', the CLABE is always masked
+func (c Customer) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", c.ID),
+		slog.String("external_id", c.ExternalID),
+		slog.String("name", c.Name),
+		slog.String("last_name", c.LastName),
+		slog.String("email", c.Email),
+		slog.String("clabe", maskClabe(c.Clabe)),
+	)
+}
+
+// Charge types without custom formatting, used to print masked copies
+type (
+	plainCharge Charge
+
+	plainChargeWithVirtualPOS struct {
+		plainCharge
+		Confirm bool
+	}
+
+	plainChargeAtStore struct {
+		plainCharge
+		DueDate time.Time
+	}
+
+	plainChargeAtBank struct {
+		plainCharge
+		DueDate time.Time
+	}
+)
+
+// Returns a copy of the charge with sensitive values masked
+func (c Charge) masked() plainCharge {
+	c.Customer.Clabe = maskClabe(c.Customer.Clabe)
+	return plainCharge(c)
+}
+
+// Format implements 'fmt.Formatter', customer bank details are always masked
+func (c Charge) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), c.masked())
+}
+
+// Returns a text representation with sensitive values masked
+func (c Charge) String() string {
+	return fmt.Sprintf("%+v", c.masked())
+}
+
+// LogValue implements 'slog.LogValuer', customer bank details are always
+// masked
+func (c Charge) LogValue() slog.Value {
+	return slog.GroupValue(c.attrs()...)
+}
+
+// Common log attributes for all charge types
+func (c Charge) attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("method", string(c.Method)),
+		slog.Any("amount", c.Amount),
+		slog.String("currency", string(c.Currency)),
+		slog.String("order_id", c.OrderID),
+		slog.Any("customer", c.Customer),
+	}
+}
+
+// Returns a copy of the charge with sensitive values masked
+func (c ChargeWithVirtualPOS) masked() plainChargeWithVirtualPOS {
+	return plainChargeWithVirtualPOS{c.Charge.masked(), c.Confirm}
+}
+
+// Format implements 'fmt.Formatter', customer bank details are always masked
+func (c ChargeWithVirtualPOS) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), c.masked())
+}
+
+// Returns a text representation with sensitive values masked
+func (c ChargeWithVirtualPOS) String() string {
+	return fmt.Sprintf("%+v", c.masked())
+}
+
+// LogValue implements 'slog.LogValuer', customer bank details are always
+// masked
+func (c ChargeWithVirtualPOS) LogValue() slog.Value {
+	return slog.GroupValue(append(c.attrs(), slog.Bool("confirm", c.Confirm))...)
+}
+
+// Returns a copy of the charge with sensitive values masked
+func (c ChargeAtStore) masked() plainChargeAtStore {
+	return plainChargeAtStore{c.Charge.masked(), c.DueDate}
+}
+
+// Format implements 'fmt.Formatter', customer bank details are always masked
+func (c ChargeAtStore) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), c.masked())
+}
+
+// Returns a text representation with sensitive values masked
+func (c ChargeAtStore) String() string {
+	return fmt.Sprintf("%+v", c.masked())
+}
+
+// LogValue implements 'slog.LogValuer', customer bank details are always
+// masked
+func (c ChargeAtStore) LogValue() slog.Value {
+	return slog.GroupValue(append(c.attrs(), slog.Time("due_date", c.DueDate))...)
+}
+
+// Returns a copy of the charge with sensitive values masked
+func (c ChargeAtBank) masked() plainChargeAtBank {
+	return plainChargeAtBank{c.Charge.masked(), c.DueDate}
+}
+
+// Format implements 'fmt.Formatter', customer bank details are always masked
+func (c ChargeAtBank) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), c.masked())
+}
+
+// Returns a text representation with sensitive values masked
+func (c ChargeAtBank) String() string {
+	return fmt.Sprintf("%+v", c.masked())
+}
+
+// LogValue implements 'slog.LogValuer', customer bank details are always
+// masked
+func (c ChargeAtBank) LogValue() slog.Value {
+	return slog.GroupValue(append(c.attrs(), slog.Time("due_date", c.DueDate))...)
+}
@@ -0,0 +1,120 @@
+package openpay
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	card := Card{
+		HolderName: "Rick Sanchez",
+		CardNumber: "4111111111111111",
+		CVV2:       "401",
+	}
+	charge := &ChargeWithStoredCard{SourceID: "card-id", CVV2: "401"}
+
+	// Formatting
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		for _, v := range []interface{}{card, &card, charge} {
+			out := fmt.Sprintf(format, v)
+			if strings.Contains(out, "4111111111111111") || strings.Contains(out, "401") {
+				t.Errorf("%s: sensitive data not masked: %s", format, out)
+			}
+		}
+	}
+	if !strings.Contains(card.String(), "411111XXXXXX1111") {
+		t.Errorf("invalid masked value: %s", card)
+	}
+
+	// Logging
+	buf := &bytes.Buffer{}
+	log := slog.New(slog.NewJSONHandler(buf, nil))
+	log.Info("card registered", "card", card, "charge", charge)
+	if strings.Contains(buf.String(), "4111111111111111") || strings.Contains(buf.String(), `"401"`) {
+		t.Errorf("sensitive data not masked: %s", buf)
+	}
+
+	// JSON payloads
+	out := string(RedactJSON([]byte(`{"source_id":"x","card":{"card_number":"4111111111111111","cvv2":"401"},"list":[{"password":"secret"}]}`)))
+	for _, secret := range []string{"4111111111111111", "401", "secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("sensitive data not masked: %s", out)
+		}
+	}
+	if !strings.Contains(out, "411111XXXXXX1111") {
+		t.Errorf("invalid masked value: %s", out)
+	}
+	if string(RedactJSON([]byte("not json"))) != "not json" {
+		t.Error("invalid content modified")
+	}
+
+	// Values are scrubbed whatever their type
+	out = string(RedactJSON([]byte(`{"cvv2":401,"amount":100.5,"card":{"card_number":4111111111111111},"password":{"v":"secret"}}`)))
+	for _, secret := range []string{"4111111111111111", "401", "secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("sensitive data not masked: %s", out)
+		}
+	}
+	if !strings.Contains(out, `"card_number":"411111XXXXXX1111"`) || !strings.Contains(out, `"amount":100.5`) {
+		t.Errorf("invalid masked value: %s", out)
+	}
+
+	// Bank accounts
+	out = string(RedactJSON([]byte(`{"holder_name":"Rick","clabe":"012298026516924616"}`)))
+	if !strings.Contains(out, `"clabe":"012XXXXXXXXXX24616"`) {
+		t.Errorf("invalid masked value: %s", out)
+	}
+}
+
+func TestRedactionBankDetails(t *testing.T) {
+	const clabe = "012298026516924616"
+	customer := Customer{Name: "Rick", Clabe: clabe}
+	charge := Charge{Method: MethodBankAccount, Amount: 100, Customer: customer}
+	values := []interface{}{
+		customer,
+		&customer,
+		BankAccount{HolderName: "Rick Sanchez", Clabe: clabe},
+		charge,
+		&ChargeWithVirtualPOS{Charge: charge},
+		&ChargeWithStoredCard{Charge: charge, SourceID: "card-id", CVV2: "401"},
+		&ChargeAtStore{Charge: charge},
+		ChargeAtBank{Charge: charge},
+	}
+
+	// Formatting
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		for _, v := range values {
+			out := fmt.Sprintf(format, v)
+			if strings.Contains(out, clabe) || strings.Contains(out, "401") {
+				t.Errorf("%s: sensitive data not masked: %s", format, out)
+			}
+		}
+	}
+	if !strings.Contains(customer.String(), "012XXXXXXXXXX24616") {
+		t.Errorf("invalid masked value: %s", customer)
+	}
+
+	// Fields beyond the base charge are kept
+	if out := fmt.Sprintf("%+v", values[5]); !strings.Contains(out, "SourceID:card-id") {
+		t.Errorf("missing charge details: %s", out)
+	}
+	if out := fmt.Sprintf("%+v", values[7]); !strings.Contains(out, "DueDate:") {
+		t.Errorf("missing charge details: %s", out)
+	}
+
+	// Logging
+	buf := &bytes.Buffer{}
+	log := slog.New(slog.NewJSONHandler(buf, nil))
+	for _, v := range values {
+		log.Info("charge", "value", v)
+	}
+	if strings.Contains(buf.String(), clabe) || strings.Contains(buf.String(), `"401"`) {
+		t.Errorf("sensitive data not masked: %s", buf)
+	}
+	if !strings.Contains(buf.String(), `"clabe":"012XXXXXXXXXX24616"`) {
+		t.Errorf("invalid masked value: %s", buf)
+	}
+}
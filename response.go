@@ -0,0 +1,43 @@
+package openpay
+
+import (
+	"net/http"
+	"time"
+)
+
+// Response provides the details of the last request sent to the service, it
+// can be captured for any operation using 'Client.WithResponse'. Values are
+// provided both for successful and failed requests; requests rejected locally,
+// for example by validation, leave it empty.
+type Response struct {
+	// HTTP status code, 0 if no response was received
+	StatusCode int
+
+	// HTTP headers included in the response
+	Header http.Header
+
+	// Unique identifier assigned by the service, useful when contacting
+	// support
+	RequestID string
+
+	// Time required to complete the request
+	Latency time.Duration
+
+	// Number of attempts executed
+	Attempts int
+
+	// Raw response content, before decoding
+	Body []byte
+}
+
+// Return the metadata for a request attempt
+func (res *result) metadata() Response {
+	return Response{
+		StatusCode: res.status,
+		Header:     res.header,
+		RequestID:  res.requestID,
+		Latency:    res.latency,
+		Attempts:   res.attempt,
+		Body:       res.body,
+	}
+}
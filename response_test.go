@@ -0,0 +1,44 @@
+package openpay_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
+)
+
+func TestResponse(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	meta := openpay.Response{}
+	whs, err := client.WithResponse(&meta).Webhooks.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.StatusCode != 200 || meta.RequestID == "" || meta.Attempts != 1 || meta.Latency <= 0 {
+		t.Errorf("invalid metadata: %+v", meta)
+	}
+	if meta.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("invalid headers: %v", meta.Header)
+	}
+	var raw []openpay.Webhook
+	if err := json.Unmarshal(meta.Body, &raw); err != nil || len(raw) != len(whs) {
+		t.Errorf("invalid body: %s", meta.Body)
+	}
+
+	// Failed requests
+	_, err = client.WithResponse(&meta).Charges.Get("unknown")
+	apiErr, ok := err.(*openpay.APIError)
+	if !ok || meta.StatusCode != 404 || meta.RequestID != apiErr.RequestID {
+		t.Errorf("invalid metadata: %+v", meta)
+	}
+
+	// Requests rejected locally
+	err = client.WithResponse(&meta).Customers.Create(&openpay.Customer{})
+	if err == nil || meta.StatusCode != 0 || meta.RequestID != "" {
+		t.Errorf("metadata should be empty: %+v", meta)
+	}
+}
@@ -0,0 +1,284 @@
+package openpay
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrSettlementExpired is returned when a charge is still pending once its
+// due date has passed
+var ErrSettlementExpired = errors.New("charge not settled before its due date")
+
+// SettlementOptions adjust how pending charges are polled
+type SettlementOptions struct {
+	// Initial time between status checks, 5 seconds if not provided; it is
+	// doubled after every check
+	Interval time.Duration
+
+	// Maximum time between status checks, 5 minutes if not provided
+	MaxInterval time.Duration
+
+	// Additional time to keep waiting after the due date of a charge, to
+	// allow for late payment notifications
+	Grace time.Duration
+
+	// Webhook events received for the charges, used to stop polling as soon
+	// as a final status is reported
+	Events <-chan *Event
+
+	// Called when a charge reaches a final status, or polling stops because
+	// of an error, in which case the transaction may be nil; used by
+	// 'SettlementWatcher'
+	OnSettled func(txID string, tx *Transaction, err error)
+}
+
+// SettlementWatcher polls many pending charges, for example store and bank
+// charges waiting for the customer payment, until each one reaches a final
+// status or its due date passes. Charges can be added at any time, including
+// while 'Run' is active; since 'Run' returns once no charges are pending, it
+// must be started again to poll charges added after that. It is safe for
+// concurrent use.
+type SettlementWatcher struct {
+	api     ChargesAPI
+	opts    SettlementOptions
+	mu      sync.Mutex
+	pending map[string]*settlement
+	wake    chan struct{}
+}
+
+// Polling status for a single charge
+type settlement struct {
+	next     time.Time
+	interval time.Duration
+	due      time.Time
+	last     *Transaction
+}
+
+// WaitForSettlement polls a charge until it reaches a final status, returning
+// the final transaction. Waiting stops when the context is done or the charge
+// due date passes, in which case 'ErrSettlementExpired' is returned along with
+// the last known transaction. To also bound each request use a client created
+// with 'WithContext'.
+func WaitForSettlement(ctx context.Context, api ChargesAPI, txID string, opts *SettlementOptions) (*Transaction, error) {
+	var o SettlementOptions
+	if opts != nil {
+		o = *opts
+	}
+	var tx *Transaction
+	var err error
+	settled := o.OnSettled
+	o.OnSettled = func(id string, t *Transaction, e error) {
+		tx, err = t, e
+		if settled != nil {
+			settled(id, t, e)
+		}
+	}
+	w := NewSettlementWatcher(api, &o)
+	w.Add(txID)
+	if runErr := w.Run(ctx); runErr != nil {
+		return nil, runErr
+	}
+	return tx, err
+}
+
+// NewSettlementWatcher returns a watcher with no pending charges
+func NewSettlementWatcher(api ChargesAPI, opts *SettlementOptions) *SettlementWatcher {
+	w := &SettlementWatcher{
+		api:     api,
+		pending: make(map[string]*settlement),
+		wake:    make(chan struct{}, 1),
+	}
+	if opts != nil {
+		w.opts = *opts
+	}
+	if w.opts.Interval == 0 {
+		w.opts.Interval = 5 * time.Second
+	}
+	if w.opts.MaxInterval == 0 {
+		w.opts.MaxInterval = 5 * time.Minute
+	}
+	return w
+}
+
+// Add a charge to watch, it is checked right away
+func (w *SettlementWatcher) Add(txIDs ...string) {
+	w.mu.Lock()
+	for _, id := range txIDs {
+		if _, ok := w.pending[id]; !ok {
+			w.pending[id] = &settlement{next: time.Now(), interval: w.opts.Interval}
+		}
+	}
+	w.mu.Unlock()
+	w.signal()
+}
+
+// Pending returns the number of charges not settled yet
+func (w *SettlementWatcher) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// Notify the watcher about a webhook event; if it reports a final status for
+// a watched charge, polling stops for it right away, other changes trigger an
+// immediate status check
+func (w *SettlementWatcher) Notify(ev *Event) {
+	if ev == nil || ev.Transaction == nil {
+		return
+	}
+	tx := ev.Transaction
+	w.mu.Lock()
+	st, ok := w.pending[tx.ID]
+	if ok && tx.Status.IsFinal() {
+		delete(w.pending, tx.ID)
+	} else if ok {
+		st.next = time.Now()
+	}
+	w.mu.Unlock()
+	if !ok {
+		return
+	}
+	if tx.Status.IsFinal() {
+		w.settle(tx.ID, tx, nil)
+		return
+	}
+	w.signal()
+}
+
+// Run polls all pending charges until every one of them is settled, or the
+// context is done; charges added later require calling it again
+func (w *SettlementWatcher) Run(ctx context.Context) error {
+	for {
+		w.mu.Lock()
+		if len(w.pending) == 0 {
+			w.mu.Unlock()
+			return nil
+		}
+		now := time.Now()
+		var due []string
+		next := now.Add(w.opts.MaxInterval)
+		for id, st := range w.pending {
+			if !st.next.After(now) {
+				due = append(due, id)
+			} else if st.next.Before(next) {
+				next = st.next
+			}
+		}
+		w.mu.Unlock()
+
+		for _, id := range due {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			w.check(id)
+		}
+		if len(due) > 0 {
+			continue
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		case <-w.wake:
+			timer.Stop()
+		case ev, ok := <-w.opts.Events:
+			timer.Stop()
+			if !ok {
+				w.opts.Events = nil
+				continue
+			}
+			w.Notify(ev)
+		}
+	}
+}
+
+// Verify the current status of a pending charge
+func (w *SettlementWatcher) check(txID string) {
+	tx, err := w.api.Get(txID)
+
+	w.mu.Lock()
+	st, ok := w.pending[txID]
+	if !ok {
+		// Settled by an event while checking
+		w.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	switch {
+	case err != nil && !retryable(err):
+		delete(w.pending, txID)
+	case err == nil && tx.Status.IsFinal():
+		delete(w.pending, txID)
+	default:
+		if err == nil {
+			st.due = tx.DueDate
+		}
+		if !st.due.IsZero() && now.After(st.due.Add(w.opts.Grace)) {
+			// Also expire when the last check failed, the last known
+			// transaction is reported
+			delete(w.pending, txID)
+			if err != nil {
+				tx = st.last
+			}
+			err = ErrSettlementExpired
+			break
+		}
+		if err == nil {
+			st.last = tx
+		}
+		st.next = now.Add(st.interval)
+		if !st.due.IsZero() {
+			// Run a last check right after the due date
+			if limit := st.due.Add(w.opts.Grace); st.next.After(limit) {
+				st.next = limit.Add(time.Millisecond)
+			}
+		}
+		st.interval *= 2
+		if st.interval > w.opts.MaxInterval {
+			st.interval = w.opts.MaxInterval
+		}
+		w.mu.Unlock()
+		return
+	}
+	w.mu.Unlock()
+	w.settle(txID, tx, err)
+}
+
+// Report a charge no longer watched
+func (w *SettlementWatcher) settle(txID string, tx *Transaction, err error) {
+	if w.opts.OnSettled != nil {
+		w.opts.OnSettled(txID, tx, err)
+	}
+}
+
+// Wake up the polling loop
+func (w *SettlementWatcher) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Temporary failures don't stop polling: network errors, throttling, server
+// errors and open circuit breakers
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPCode >= 500 || apiErr.HTTPCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	var open *CircuitOpenError
+	return errors.As(err, &netErr) || errors.As(err, &urlErr) || errors.As(err, &open)
+}
@@ -0,0 +1,131 @@
+package openpay_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fairbank-io/openpay"
+	"github.com/fairbank-io/openpay/openpaytest"
+)
+
+func TestSettlement(t *testing.T) {
+	srv := openpaytest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	opts := &openpay.SettlementOptions{Interval: 5 * time.Millisecond, MaxInterval: 20 * time.Millisecond}
+
+	storeCharge := func(due time.Time) *openpay.Transaction {
+		tx, err := client.Charges.AtStore(&openpay.ChargeAtStore{
+			Charge:  openpay.Charge{Method: openpay.MethodStore, Amount: 100},
+			DueDate: due,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	t.Run("Wait", func(t *testing.T) {
+		tx := storeCharge(time.Time{})
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			srv.SetChargeStatus(tx.ID, openpay.StatusCompleted)
+		}()
+		final, err := openpay.WaitForSettlement(context.Background(), client.Charges, tx.ID, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if final.ID != tx.ID || final.Status != openpay.StatusCompleted {
+			t.Errorf("unexpected result: %+v", final)
+		}
+	})
+
+	t.Run("DueDate", func(t *testing.T) {
+		tx := storeCharge(time.Now().Add(40 * time.Millisecond))
+		final, err := openpay.WaitForSettlement(context.Background(), client.Charges, tx.ID, opts)
+		if err != openpay.ErrSettlementExpired || final == nil || final.Status != openpay.StatusInProgress {
+			t.Errorf("expected expired error, got %v", err)
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		tx := storeCharge(time.Time{})
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+		_, err := openpay.WaitForSettlement(ctx, client.Charges, tx.ID, opts)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline error, got %v", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := openpay.WaitForSettlement(context.Background(), client.Charges, "unknown", opts)
+		if e, ok := err.(*openpay.APIError); !ok || e.HTTPCode != 404 {
+			t.Errorf("unexpected result: %v", err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		mocks := openpaytest.NewMocks()
+		mocks.Charges.GetFunc = func(txID string) (*openpay.Transaction, error) {
+			return nil, errors.New("environment variable OPENPAY_KEY is not set")
+		}
+		_, err := openpay.WaitForSettlement(context.Background(), mocks.Charges, "tx1", opts)
+		if err == nil || err == openpay.ErrSettlementExpired {
+			t.Errorf("unexpected result: %v", err)
+		}
+		mocks.Charges.AssertNumberOfCalls(t, "Get", 1)
+	})
+
+	t.Run("DueDateWithErrors", func(t *testing.T) {
+		mocks := openpaytest.NewMocks()
+		pending := &openpay.Transaction{ID: "tx1", Status: openpay.StatusInProgress, DueDate: time.Now().Add(30 * time.Millisecond)}
+		mocks.Charges.GetFunc = func(txID string) (*openpay.Transaction, error) {
+			if len(mocks.Charges.CallsTo("Get")) == 1 {
+				return pending, nil
+			}
+			return nil, &openpay.APIError{Code: 1000, Category: "internal", HTTPCode: 500}
+		}
+		final, err := openpay.WaitForSettlement(context.Background(), mocks.Charges, "tx1", opts)
+		if err != openpay.ErrSettlementExpired || final != pending {
+			t.Errorf("expected expired error, got %v", err)
+		}
+	})
+
+	t.Run("Watcher", func(t *testing.T) {
+		var mu sync.Mutex
+		settled := make(map[string]openpay.TransactionStatus)
+		events := make(chan *openpay.Event, 1)
+		w := openpay.NewSettlementWatcher(client.Charges, &openpay.SettlementOptions{
+			// Polling is slow, events are used to stop early
+			Interval: time.Hour,
+			Events:   events,
+			OnSettled: func(txID string, tx *openpay.Transaction, err error) {
+				mu.Lock()
+				settled[txID] = tx.Status
+				mu.Unlock()
+			},
+		})
+		paid, cancelled := storeCharge(time.Time{}), storeCharge(time.Time{})
+		w.Add(paid.ID, cancelled.ID)
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			srv.SetChargeStatus(paid.ID, openpay.StatusCompleted)
+			events <- &openpay.Event{Type: openpay.EventChargeSucceeded, Transaction: &openpay.Transaction{ID: paid.ID, Status: openpay.StatusInProgress}}
+			events <- &openpay.Event{Type: openpay.EventChargeCancelled, Transaction: &openpay.Transaction{ID: cancelled.ID, Status: openpay.StatusCancelled}}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := w.Run(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if settled[paid.ID] != openpay.StatusCompleted || settled[cancelled.ID] != openpay.StatusCancelled || w.Pending() != 0 {
+			t.Errorf("unexpected results: %v", settled)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package openpay
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Allowed changes for each transaction status; a status can always remain
+// unchanged
+var statusTransitions = map[TransactionStatus][]TransactionStatus{
+	StatusChargePending:        {StatusInProgress, StatusCompleted, StatusFailed, StatusCancelled},
+	StatusInProgress:           {StatusCompleted, StatusFailed, StatusCancelled},
+	StatusCompleted:            {StatusRefunded, StatusChargebackPending},
+	StatusChargebackPending:    {StatusChargebackAccepted, StatusChargebackAdjustment, StatusCompleted},
+	StatusChargebackAccepted:   nil,
+	StatusChargebackAdjustment: nil,
+	StatusRefunded:             nil,
+	StatusFailed:               nil,
+	StatusCancelled:            nil,
+}
+
+// ParseTransactionStatus returns the status for a text value in any of the
+// formats used by the service, for example 'in_progress' or 'IN_PROGRESS'
+func ParseTransactionStatus(v string) (TransactionStatus, error) {
+	s := TransactionStatus(v).Normalize()
+	if !s.Valid() {
+		return s, fmt.Errorf("unknown transaction status: %s", v)
+	}
+	return s, nil
+}
+
+// Normalize returns the status using the documented format, lowercase with
+// words separated by underscores
+func (v TransactionStatus) Normalize() TransactionStatus {
+	s := strings.ToLower(strings.TrimSpace(string(v)))
+	return TransactionStatus(strings.NewReplacer(" ", "_", "-", "_").Replace(s))
+}
+
+// UnmarshalText implements 'encoding.TextUnmarshaler', values are normalized
+// when decoded
+func (v *TransactionStatus) UnmarshalText(b []byte) error {
+	*v = TransactionStatus(b).Normalize()
+	return nil
+}
+
+// IsPending returns true while the transaction is still being processed
+func (v TransactionStatus) IsPending() bool {
+	switch v.Normalize() {
+	case StatusInProgress, StatusChargePending, StatusChargebackPending:
+		return true
+	}
+	return false
+}
+
+// IsFinal returns true once the processing of the transaction is done; note
+// completed charges can still be refunded or disputed later on
+func (v TransactionStatus) IsFinal() bool {
+	return v.Normalize().Valid() && !v.IsPending()
+}
+
+// IsSuccessful returns true if the funds were collected
+func (v TransactionStatus) IsSuccessful() bool {
+	return v.Normalize() == StatusCompleted
+}
+
+// Refundable returns true if a charge in this status can be refunded
+func (v TransactionStatus) Refundable() bool {
+	return v.Normalize() == StatusCompleted
+}
+
+// Capturable returns true if a pre-authorized card charge in this status can
+// be captured
+func (v TransactionStatus) Capturable() bool {
+	return v.Normalize() == StatusInProgress
+}
+
+// CanTransition returns true if a transaction can change from this status to
+// the provided one
+func (v TransactionStatus) CanTransition(to TransactionStatus) bool {
+	from, to := v.Normalize(), to.Normalize()
+	if from == to {
+		return true
+	}
+	for _, s := range statusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionError reports an invalid status change for a transaction
+type TransitionError struct {
+	TransactionID string
+	From          TransactionStatus
+	To            TransactionStatus
+}
+
+// Returns a descriptive text representation
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("transaction %s: invalid status change from %s to %s", e.TransactionID, e.From, e.To)
+}
+
+// StatusTracker keeps the last known status of transactions and validates
+// every change seen, for example across 'Charges.Get' calls and webhook
+// events. It is safe for concurrent use.
+type StatusTracker struct {
+	mu     sync.Mutex
+	status map[string]TransactionStatus
+}
+
+// NewStatusTracker returns an empty tracker
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{status: make(map[string]TransactionStatus)}
+}
+
+// Status returns the last known status for a transaction
+func (st *StatusTracker) Status(txID string) (TransactionStatus, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.status[txID]
+	return s, ok
+}
+
+// Observe registers the current status of a transaction; a 'TransitionError'
+// is returned, and the change ignored, if it isn't allowed from the last known
+// status
+func (st *StatusTracker) Observe(tx *Transaction) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	to := tx.Status.Normalize()
+	if from, ok := st.status[tx.ID]; ok && !from.CanTransition(to) {
+		return &TransitionError{TransactionID: tx.ID, From: from, To: to}
+	}
+	st.status[tx.ID] = to
+	return nil
+}
+
+// ObserveEvent registers the transaction included in a webhook event, if any
+func (st *StatusTracker) ObserveEvent(ev *Event) error {
+	if ev.Transaction == nil || ev.Transaction.ID == "" {
+		return nil
+	}
+	return st.Observe(ev.Transaction)
+}
+
+// MarshalJSON implements 'json.Marshaler', the status filter is sent using the
+// format expected by the service
+func (r ChargesListRequest) MarshalJSON() ([]byte, error) {
+	type plain ChargesListRequest
+	p := plain(r)
+	p.Status = TransactionStatus(strings.ToUpper(string(r.Status.Normalize())))
+	return json.Marshal(p)
+}
@@ -0,0 +1,76 @@
+package openpay
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTransactionStatus(t *testing.T) {
+	s, err := ParseTransactionStatus("CHARGEBACK_PENDING")
+	if err != nil || s != StatusChargebackPending {
+		t.Errorf("unexpected result: %s, %v", s, err)
+	}
+	if _, err := ParseTransactionStatus("on_hold"); err == nil {
+		t.Error("expected error for unknown status")
+	}
+
+	// Values are normalized when decoded, and sent uppercase on filters
+	tx := &Transaction{}
+	json.Unmarshal([]byte(`{"status":"IN_PROGRESS"}`), tx)
+	if tx.Status != StatusInProgress {
+		t.Errorf("status not normalized: %s", tx.Status)
+	}
+	b, _ := json.Marshal(&ChargesListRequest{Status: StatusChargePending, OrderID: "o1"})
+	if !strings.Contains(string(b), `"status":"CHARGE_PENDING"`) || !strings.Contains(string(b), `"order_id":"o1"`) {
+		t.Errorf("unexpected encoding: %s", b)
+	}
+
+	// Predicates
+	cases := []struct {
+		status                                    TransactionStatus
+		final, successful, refundable, capturable bool
+	}{
+		{StatusInProgress, false, false, false, true},
+		{StatusCompleted, true, true, true, false},
+		{"COMPLETED", true, true, true, false},
+		{StatusFailed, true, false, false, false},
+		{StatusRefunded, true, false, false, false},
+		{StatusChargebackPending, false, false, false, false},
+		{"on_hold", false, false, false, false},
+	}
+	for _, c := range cases {
+		if c.status.IsFinal() != c.final || c.status.IsSuccessful() != c.successful ||
+			c.status.Refundable() != c.refundable || c.status.Capturable() != c.capturable {
+			t.Errorf("invalid predicates for %s", c.status)
+		}
+	}
+
+	// Transitions
+	if !StatusInProgress.CanTransition(StatusCompleted) || !StatusCompleted.CanTransition("REFUNDED") ||
+		StatusRefunded.CanTransition(StatusCompleted) || StatusFailed.CanTransition(StatusInProgress) {
+		t.Error("invalid transitions")
+	}
+}
+
+func TestStatusTracker(t *testing.T) {
+	st := NewStatusTracker()
+	if err := st.Observe(&Transaction{ID: "tx1", Status: StatusInProgress}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.ObserveEvent(&Event{Type: EventChargeSucceeded, Transaction: &Transaction{ID: "tx1", Status: "completed"}}); err != nil {
+		t.Fatal(err)
+	}
+	err := st.Observe(&Transaction{ID: "tx1", Status: StatusInProgress})
+	var te *TransitionError
+	if !errors.As(err, &te) || te.From != StatusCompleted || te.To != StatusInProgress {
+		t.Errorf("expected transition error, got %v", err)
+	}
+	if s, _ := st.Status("tx1"); s != StatusCompleted {
+		t.Errorf("invalid changes should be ignored, got %s", s)
+	}
+	if err := st.ObserveEvent(&Event{Type: EventVerification}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,86 @@
+package openpay
+
+// Card numbers provided by the sandbox environment to trigger specific results
+// https://www.openpay.mx/docs/testing.html
+type TestCard struct {
+	// Full card number
+	Number string
+
+	// Card brand
+	Brand CardBrand
+
+	// Error code reported when charging the card, 0 if the charge is approved
+	ErrorCode uint
+
+	// Error description reported when charging the card, if any
+	Description string
+
+	// Potential fraud flags reported when charging the card, if any
+	FraudRules []string
+}
+
+// SandboxCards lists the test cards supported by the sandbox environment
+var SandboxCards = []TestCard{
+	{Number: "4111111111111111", Brand: BrandVisa},
+	{Number: "4242424242424242", Brand: BrandVisa},
+	{Number: "5555555555554444", Brand: BrandMastercard},
+	{Number: "5105105105105100", Brand: BrandMastercard},
+	{Number: "345678000000007", Brand: BrandAmericanExpress},
+	{Number: "343434343434343", Brand: BrandAmericanExpress},
+	{
+		Number:      "4222222222222220",
+		Brand:       BrandVisa,
+		ErrorCode:   ErrCodeCardDeclined,
+		Description: "The card was declined",
+	},
+	{
+		Number:      "4000000000000069",
+		Brand:       BrandVisa,
+		ErrorCode:   ErrCodeCardExpired,
+		Description: "The card has expired",
+	},
+	{
+		Number:      "4444444444444448",
+		Brand:       BrandVisa,
+		ErrorCode:   ErrCodeInsufficientFunds,
+		Description: "The card doesn't have sufficient funds",
+	},
+	{
+		Number:      "4000000000000119",
+		Brand:       BrandVisa,
+		ErrorCode:   ErrCodeCardStolen,
+		Description: "The card was reported as stolen",
+	},
+	{
+		Number:      "4000000000000044",
+		Brand:       BrandVisa,
+		ErrorCode:   ErrCodeFraudRejected,
+		Description: "Fraud risk detected by anti-fraud system",
+		FraudRules:  []string{"Billing <> BIN Country for VISA/MC", "Velocity: more than 3 cards per device"},
+	},
+}
+
+// LookupTestCard returns the sandbox test card details for a card number, if any
+func LookupTestCard(number string) (TestCard, bool) {
+	for _, tc := range SandboxCards {
+		if tc.Number == number {
+			return tc, true
+		}
+	}
+	return TestCard{}, false
+}
+
+// ChargeError returns the service error produced when charging the card, or nil
+// if the charge is approved
+func (tc TestCard) ChargeError() *APIError {
+	if tc.ErrorCode == 0 {
+		return nil
+	}
+	return &APIError{
+		Category:    "gateway",
+		Code:        tc.ErrorCode,
+		HTTPCode:    402,
+		Description: tc.Description,
+		FraudRules:  tc.FraudRules,
+	}
+}
@@ -0,0 +1,282 @@
+package openpay
+
+import (
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError describes an invalid value for a specific field
+type FieldError struct {
+	// Field name, using the same format used by the service, for example
+	// 'card_number' or 'address.city'
+	Field string
+
+	// Problem detected with the value
+	Message string
+}
+
+// ValidationError is returned when a request is rejected locally, before
+// reaching the service; it includes all the invalid fields detected
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Returns a descriptive text representation
+func (e *ValidationError) Error() string {
+	list := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		list[i] = f.Field + ": " + f.Message
+	}
+	return "invalid request: " + strings.Join(list, "; ")
+}
+
+// Has returns true if the provided field was reported as invalid
+func (e *ValidationError) Has(field string) bool {
+	for _, f := range e.Fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Accumulates field errors
+type validator struct {
+	prefix string
+	fields []FieldError
+}
+
+// Register an invalid field
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.fields = append(v.fields, FieldError{
+		Field:   v.prefix + field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Run a nested validation using a field prefix
+func (v *validator) nested(prefix string, fn func(v *validator)) {
+	n := &validator{prefix: v.prefix + prefix + "."}
+	fn(n)
+	v.fields = append(v.fields, n.fields...)
+}
+
+// Return the accumulated errors, if any
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+// Validate the card details before registering it
+func (c *Card) Validate() error {
+	v := &validator{}
+	c.validate(v)
+	return v.err()
+}
+
+func (c *Card) validate(v *validator) {
+	if strings.TrimSpace(c.HolderName) == "" {
+		v.add("holder_name", "is required")
+	}
+
+	// Card number, valid lengths depend on the brand
+	bin, known := LookupBIN(c.CardNumber)
+	switch {
+	case c.CardNumber == "":
+		v.add("card_number", "is required")
+	case !isDigits(c.CardNumber):
+		v.add("card_number", "must contain only digits")
+	case !known && (len(c.CardNumber) < 13 || len(c.CardNumber) > 19):
+		v.add("card_number", "invalid length")
+	case known && !bin.ValidLength(len(c.CardNumber)):
+		v.add("card_number", "invalid length for %s card", bin.Brand)
+	case !luhn(c.CardNumber):
+		v.add("card_number", "failed checksum validation")
+	}
+
+	// Security code, 4 digits for american express cards
+	cvvLen := 3
+	if known {
+		cvvLen = bin.CVVLength
+	}
+	if len(c.CVV2) != cvvLen || !isDigits(c.CVV2) {
+		v.add("cvv2", "must be %d digits long", cvvLen)
+	}
+
+	// Expiration date, cards are valid until the end of the month
+	month, errM := strconv.Atoi(c.ExpirationMonth)
+	year, errY := strconv.Atoi(c.ExpirationYear)
+	if len(c.ExpirationMonth) != 2 || errM != nil || month < 1 || month > 12 {
+		v.add("expiration_month", "must be a 2 digits month")
+	}
+	if len(c.ExpirationYear) != 2 || errY != nil {
+		v.add("expiration_year", "must be a 2 digits year")
+	}
+	if errM == nil && errY == nil && month >= 1 && month <= 12 {
+		expiry := time.Date(2000+year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+		if !time.Now().Before(expiry) {
+			v.add("expiration_year", "card is expired")
+		}
+	}
+
+	if c.Address != (Address{}) {
+		v.nested("address", c.Address.validate)
+	}
+}
+
+// Validate the address contains all required values
+func (a *Address) Validate() error {
+	v := &validator{}
+	a.validate(v)
+	return v.err()
+}
+
+func (a *Address) validate(v *validator) {
+	if strings.TrimSpace(a.Line1) == "" {
+		v.add("line1", "is required")
+	}
+	if strings.TrimSpace(a.PostalCode) == "" {
+		v.add("postal_code", "is required")
+	}
+	if strings.TrimSpace(a.State) == "" {
+		v.add("state", "is required")
+	}
+	if strings.TrimSpace(a.City) == "" {
+		v.add("city", "is required")
+	}
+	if a.CountryCode != "" && len(a.CountryCode) != 2 {
+		v.add("country_code", "must be an ISO 3166-1 two letters code")
+	}
+}
+
+// Validate the customer details before registering it
+func (c *Customer) Validate() error {
+	v := &validator{}
+	c.validate(v)
+	return v.err()
+}
+
+func (c *Customer) validate(v *validator) {
+	if strings.TrimSpace(c.Name) == "" {
+		v.add("name", "is required")
+	}
+	if c.Email == "" {
+		v.add("email", "is required")
+	} else if !validEmail(c.Email) {
+		v.add("email", "invalid format")
+	}
+	if c.Address != (Address{}) {
+		v.nested("address", c.Address.validate)
+	}
+}
+
+// Validate the base charge values
+func (c *Charge) Validate() error {
+	v := &validator{}
+	c.validate(v, "")
+	return v.err()
+}
+
+func (c *Charge) validate(v *validator, method PaymentMethod) {
+	if method != "" && c.Method != method {
+		v.add("method", "must be '%s'", method)
+	} else if !c.Method.Valid() {
+		v.add("method", "invalid value")
+	}
+	if c.Amount <= 0 {
+		v.add("amount", "must be greater than zero")
+	} else if !validAmount(c.Amount) {
+		v.add("amount", "must have at most two decimal digits")
+	}
+	if c.Currency != "" && !c.Currency.Valid() {
+		v.add("currency", "must be MXN or USD")
+	}
+	if c.Customer.Email != "" && !validEmail(c.Customer.Email) {
+		v.add("customer.email", "invalid format")
+	}
+}
+
+// Validate the charge values before submitting it
+func (c *ChargeWithStoredCard) Validate() error {
+	v := &validator{}
+	c.Charge.validate(v, MethodCard)
+	if c.SourceID == "" {
+		v.add("source_id", "is required")
+	}
+	if c.CVV2 != "" && (!isDigits(c.CVV2) || len(c.CVV2) < 3 || len(c.CVV2) > 4) {
+		v.add("cvv2", "must be 3 or 4 digits long")
+	}
+	if c.UseCardPoints != "" && !c.UseCardPoints.Valid() {
+		v.add("use_card_points", "invalid value")
+	}
+	return v.err()
+}
+
+// Validate the charge values before submitting it
+func (c *ChargeAtStore) Validate() error {
+	v := &validator{}
+	c.Charge.validate(v, MethodStore)
+	validateDueDate(v, c.DueDate)
+	return v.err()
+}
+
+// Validate the charge values before submitting it
+func (c *ChargeAtBank) Validate() error {
+	v := &validator{}
+	c.Charge.validate(v, MethodBankAccount)
+	validateDueDate(v, c.DueDate)
+	return v.err()
+}
+
+// Due dates are optional, but must be in the future if provided
+func validateDueDate(v *validator, d time.Time) {
+	if !d.IsZero() && !d.After(time.Now()) {
+		v.add("due_date", "must be in the future")
+	}
+}
+
+// Amounts are limited to two decimal digits; the value is checked using its
+// shortest representation at float32 precision, the one used when encoded
+func validAmount(amount float32) bool {
+	s := strconv.FormatFloat(float64(amount), 'f', -1, 32)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s)-i-1 <= 2
+	}
+	return true
+}
+
+// Basic email format verification
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email && strings.Contains(email[strings.LastIndex(email, "@"):], ".")
+}
+
+// Luhn checksum verification
+func luhn(number string) bool {
+	sum := 0
+	for i := 0; i < len(number); i++ {
+		d := int(number[len(number)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,99 @@
+package openpay
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidation(t *testing.T) {
+	t.Run("Card", func(t *testing.T) {
+		card := testCard()
+		if err := card.Validate(); err != nil {
+			t.Error(err)
+		}
+
+		card.CardNumber = "4111111111111112"
+		card.CVV2 = "41"
+		card.ExpirationYear = "19"
+		card.Address = Address{City: "Cordoba"}
+		err, ok := card.Validate().(*ValidationError)
+		if !ok {
+			t.Fatal("expected validation error")
+		}
+		for _, f := range []string{"card_number", "cvv2", "expiration_year", "address.line1", "address.postal_code", "address.state"} {
+			if !err.Has(f) {
+				t.Errorf("invalid field not detected: %s", f)
+			}
+		}
+
+		// American express cards use 15 digits numbers and 4 digits codes
+		amex := testCard()
+		amex.CardNumber = "345678000000007"
+		if err, _ := amex.Validate().(*ValidationError); err == nil || !err.Has("cvv2") || len(err.Fields) != 1 {
+			t.Errorf("unexpected result: %v", err)
+		}
+	})
+
+	t.Run("Customer", func(t *testing.T) {
+		c := &Customer{Name: "Rick", Email: "rick@mail.com"}
+		if err := c.Validate(); err != nil {
+			t.Error(err)
+		}
+		for _, email := range []string{"rick", "rick@mail", "Rick <rick@mail.com>"} {
+			c.Email = email
+			if err, _ := c.Validate().(*ValidationError); err == nil || !err.Has("email") {
+				t.Errorf("invalid email not detected: %s", email)
+			}
+		}
+	})
+
+	t.Run("Charges", func(t *testing.T) {
+		charge := &ChargeAtStore{
+			Charge:  Charge{Method: "store", Amount: 100.25, Currency: "MXN"},
+			DueDate: time.Now().Add(time.Hour),
+		}
+		if err := charge.Validate(); err != nil {
+			t.Error(err)
+		}
+
+		charge.Amount = 10.001
+		charge.Currency = "EUR"
+		charge.DueDate = time.Now().Add(-time.Hour)
+		err, ok := charge.Validate().(*ValidationError)
+		if !ok || len(err.Fields) != 3 {
+			t.Errorf("unexpected result: %v", err)
+		}
+
+		amounts := map[float32]bool{
+			1:        true,
+			0.1:      true,
+			10.05:    true,
+			1234.56:  true,
+			5000.10:  true,
+			12345.67: true,
+			19999.99: true,
+			99999.99: true,
+			10.001:   false,
+			1000.005: false,
+		}
+		for amount, valid := range amounts {
+			c := &Charge{Method: "store", Amount: amount, Currency: "MXN"}
+			if err := c.Validate(); (err == nil) != valid {
+				t.Errorf("%v: unexpected result: %v", amount, err)
+			}
+		}
+
+		sale := &ChargeWithStoredCard{Charge: Charge{Method: "card", Amount: 0}}
+		if err, _ := sale.Validate().(*ValidationError); err == nil || !err.Has("amount") || !err.Has("source_id") {
+			t.Errorf("unexpected result: %v", err)
+		}
+	})
+
+	// Invalid requests must be rejected before reaching the service
+	client, _ := NewClient("sk_invalid", "invalid", nil)
+	if err := client.Customers.Create(&Customer{}); err == nil {
+		t.Error("failed to validate customer")
+	} else if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
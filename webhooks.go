@@ -26,54 +26,66 @@ type webhooksClient struct {
 }
 
 func (wc *webhooksClient) Create(wh *Webhook) error {
+	// Reject unsupported events before reaching the service
+	if err := validateEventTypes(wh.EventTypes); err != nil {
+		return err
+	}
+
 	b, err := wc.c.request(&requestOptions{
-		endpoint: "webhooks",
-		method:   http.MethodPost,
-		data:     wh,
+		operation: "webhooks.create",
+		endpoint:  "webhooks",
+		method:    http.MethodPost,
+		data:      wh,
 	})
 	if err != nil {
 		return err
 	}
 
-	json.Unmarshal(b, wh)
-	return nil
+	return json.Unmarshal(b, wh)
 }
 
 func (wc *webhooksClient) Get(whID string) (*Webhook, error) {
 	b, err := wc.c.request(&requestOptions{
-		endpoint: path.Join("webhooks", whID),
-		method:   http.MethodGet,
-		data:     nil,
+		operation: "webhooks.get",
+		endpoint:  path.Join("webhooks", whID),
+		method:    http.MethodGet,
+		data:      nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	w := &Webhook{}
-	json.Unmarshal(b, w)
+	if err := json.Unmarshal(b, w); err != nil {
+		return nil, err
+	}
 	return w, nil
 }
 
 func (wc *webhooksClient) List() ([]Webhook, error) {
 	b, err := wc.c.request(&requestOptions{
-		endpoint: "webhooks",
-		method:   http.MethodGet,
-		data:     nil,
+		operation: "webhooks.list",
+		endpoint:  "webhooks",
+		method:    http.MethodGet,
+		data:      nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	var list []Webhook
-	json.Unmarshal(b, &list)
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, err
+	}
 	return list, nil
 }
 
 func (wc *webhooksClient) Delete(whID string) error {
 	_, err := wc.c.request(&requestOptions{
-		endpoint: path.Join("webhooks", whID),
-		method:   http.MethodDelete,
-		data:     nil,
+		operation: "webhooks.delete",
+		endpoint:  path.Join("webhooks", whID),
+		method:    http.MethodDelete,
+		data:      nil,
 	})
 	return err
-}
\ No newline at end of file
+}